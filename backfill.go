@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/MatthiasSchild/tagger/repository"
+	"github.com/MatthiasSchild/tagger/targets"
+	"github.com/MatthiasSchild/tagger/version"
+)
+
+// backfillTag is a missing tag of a version and the commit the version first appeared in
+type backfillTag struct {
+	Tag    Tag
+	Commit string
+}
+
+// findBackfillTags walks the first parent history of the version file of the target from the oldest commit
+// and returns the versions, which are not tagged yet, with the commit they first appeared in.
+// Commits without a readable version are skipped. The tags get the prefix.
+func findBackfillTags(r repository.Repository, target string, prefix string) ([]backfillTag, error) {
+	reader, ok := targets.Readers[target]
+	if !ok {
+		return nil, fmt.Errorf("unknown target %s, use one of npm, flutter, cargo, helm, dotnet or xcode", target)
+	}
+	path := targets.ManifestPaths[target]
+	if target == "dotnet" || target == "xcode" {
+		// The project files can be anywhere below the current directory
+		path = "."
+	}
+
+	commits, err := r.Commits("HEAD", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the history of %s: %v", path, err)
+	}
+	names, err := r.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch git tags: %v", err)
+	}
+	index := version.NewIndex(names, prefix)
+
+	result := make([]backfillTag, 0)
+	seen := make(map[string]bool)
+	for i := len(commits) - 1; i >= 0; i-- {
+		files, err := r.Files(commits[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the files of %s: %v", commits[i], err)
+		}
+		tag, err := reader(files)
+		if err != nil {
+			continue
+		}
+
+		tag = tag.Clone()
+		tag.Prefix = prefix
+		if seen[tag.Version()] || index.Contains(tag) {
+			continue
+		}
+		seen[tag.Version()] = true
+		result = append(result, backfillTag{Tag: tag, Commit: commits[i]})
+	}
+	return result, nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/MatthiasSchild/tagger/repository"
+)
+
+func TestFindBackfillTags(t *testing.T) {
+	memory := repository.NewMemory()
+	commit := func(version string) string {
+		memory.WriteFile("package.json", `{"name": "app", "version": "`+version+`"}`)
+		if err := memory.CommitAll("release " + version); err != nil {
+			t.Fatal(err)
+		}
+		hash, _ := memory.Resolve("HEAD")
+		return hash
+	}
+
+	first := commit("1.0.0")
+	tagged := commit("1.1.0")
+	memory.WriteFile("README.md", "docs")
+	if err := memory.CommitAll("docs"); err != nil {
+		t.Fatal(err)
+	}
+	commit("not a version")
+	third := commit("1.2.0")
+	commit("1.2.0-rc1")
+	if err := memory.CreateTag("rel-1.1.0", tagged, ""); err != nil {
+		t.Fatal(err)
+	}
+	// Tags of another prefix do not count
+	if err := memory.CreateTag("v1.0.0", first, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	missing, err := findBackfillTags(memory, "npm", "rel-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 2 {
+		t.Fatalf("expected 2 missing tags, got %+v", missing)
+	}
+	if missing[0].Tag.String() != "rel-1.0.0" || missing[0].Commit != first {
+		t.Errorf("expected rel-1.0.0 at the first commit, got %s at %s", missing[0].Tag, missing[0].Commit)
+	}
+	if missing[1].Tag.String() != "rel-1.2.0" || missing[1].Commit != third {
+		t.Errorf("expected rel-1.2.0 where it first appeared, got %s at %s", missing[1].Tag, missing[1].Commit)
+	}
+
+	if _, err := findBackfillTags(memory, "python", "rel-"); err == nil {
+		t.Error("expected a target without reader to fail")
+	}
+}
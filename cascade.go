@@ -0,0 +1,462 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/MatthiasSchild/tagger/targets"
+	"github.com/MatthiasSchild/tagger/utils"
+	"github.com/MatthiasSchild/tagger/version"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+var goModModuleRegex = regexp.MustCompile(`(?m)^\s*module\s+(\S+)`)
+var goModReplaceRegex = regexp.MustCompile(`(?m)^\s*(?:replace\s+)?(\S+)(?:\s+v\S+)?\s+=>\s+(\.\.?/\S*|/\S+)\s*$`)
+
+// dependencyEdge describes the dependency of a component on another component
+type dependencyEdge struct {
+	Dependency string // name of the component depended on
+	Kind       string // manifest of the dependency: npm, cargo, flutter or go
+	Name       string // name of the package within the manifest
+}
+
+// releaseStep describes the release of a single component within a cascade
+type releaseStep struct {
+	Component string
+	Previous  Tag
+	Next      Tag
+	Edges     []dependencyEdge
+}
+
+// componentDir returns the absolute, cleaned path of a component
+func componentDir(component ComponentConfig) string {
+	return filepath.Clean(filepath.Join(rootDir, component.Path))
+}
+
+// buildDependencyGraph reads the manifests of all components and returns
+// the dependencies on other components by the name of the dependent component.
+// Dependencies are detected by package.json dependencies, Cargo and pubspec path dependencies
+// and go.mod replace directives. Development dependencies are not part of a release, so they are skipped.
+func buildDependencyGraph() (map[string][]dependencyEdge, error) {
+	npmNames := make(map[string]string)
+	componentsByDir := make(map[string]string)
+
+	for name, component := range config.Components {
+		dir := componentDir(component)
+		componentsByDir[dir] = name
+
+		packageData := &struct {
+			Name string `json:"name"`
+		}{}
+		if content, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+			if err := json.Unmarshal(content, packageData); err != nil {
+				return nil, fmt.Errorf("failed to parse package.json of %s: %v", name, err)
+			}
+			if packageData.Name != "" {
+				npmNames[packageData.Name] = name
+			}
+		}
+	}
+
+	result := make(map[string][]dependencyEdge)
+	for name, component := range config.Components {
+		dir := componentDir(component)
+		edges := make([]dependencyEdge, 0)
+
+		addEdge := func(dependency string, kind string, packageName string) {
+			if dependency == "" || dependency == name {
+				return
+			}
+			edge := dependencyEdge{Dependency: dependency, Kind: kind, Name: packageName}
+			for _, existing := range edges {
+				if existing == edge {
+					return
+				}
+			}
+			edges = append(edges, edge)
+		}
+		resolvePath := func(path string) string {
+			return componentsByDir[filepath.Clean(filepath.Join(dir, path))]
+		}
+
+		if content, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+			packageData := &struct {
+				Dependencies         map[string]string `json:"dependencies"`
+				PeerDependencies     map[string]string `json:"peerDependencies"`
+				OptionalDependencies map[string]string `json:"optionalDependencies"`
+			}{}
+			if err := json.Unmarshal(content, packageData); err != nil {
+				return nil, fmt.Errorf("failed to parse package.json of %s: %v", name, err)
+			}
+			for _, dependencies := range []map[string]string{
+				packageData.Dependencies,
+				packageData.PeerDependencies,
+				packageData.OptionalDependencies,
+			} {
+				for packageName := range dependencies {
+					addEdge(npmNames[packageName], "npm", packageName)
+				}
+			}
+		}
+
+		if content, err := os.ReadFile(filepath.Join(dir, "Cargo.toml")); err == nil {
+			cargoData := make(map[string]any)
+			if err := toml.Unmarshal(content, &cargoData); err != nil {
+				return nil, fmt.Errorf("failed to parse Cargo.toml of %s: %v", name, err)
+			}
+			for _, section := range []string{"dependencies", "build-dependencies"} {
+				dependencies, _ := cargoData[section].(map[string]any)
+				for packageName, dependency := range dependencies {
+					details, _ := dependency.(map[string]any)
+					if path, ok := details["path"].(string); ok {
+						addEdge(resolvePath(path), "cargo", packageName)
+					}
+				}
+			}
+		}
+
+		if content, err := os.ReadFile(filepath.Join(dir, "pubspec.yaml")); err == nil {
+			pubspecData := &struct {
+				Dependencies map[string]any `yaml:"dependencies"`
+			}{}
+			if err := yaml.Unmarshal(content, pubspecData); err != nil {
+				return nil, fmt.Errorf("failed to parse pubspec.yaml of %s: %v", name, err)
+			}
+			for packageName, dependency := range pubspecData.Dependencies {
+				details, _ := dependency.(map[string]any)
+				if path, ok := details["path"].(string); ok {
+					addEdge(resolvePath(path), "flutter", packageName)
+				}
+			}
+		}
+
+		if content, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+			for _, match := range goModReplaceRegex.FindAllStringSubmatch(string(content), -1) {
+				addEdge(resolvePath(match[2]), "go", match[1])
+			}
+		}
+
+		sort.Slice(edges, func(i, j int) bool {
+			return edges[i].Dependency+edges[i].Kind < edges[j].Dependency+edges[j].Kind
+		})
+		result[name] = edges
+	}
+
+	return result, nil
+}
+
+// planCascade returns the release steps in topological order:
+// the start component with its new tag, followed by all components depending on it (directly or transitive),
+// which get a patch release.
+func planCascade(graph map[string][]dependencyEdge, start string, previous Tag, next Tag) ([]releaseStep, error) {
+	dependents := make(map[string][]string)
+	for name, edges := range graph {
+		for _, edge := range edges {
+			if !slices.Contains(dependents[edge.Dependency], name) {
+				dependents[edge.Dependency] = append(dependents[edge.Dependency], name)
+			}
+		}
+	}
+
+	affected := map[string]bool{start: true}
+	queue := []string{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dependent := range dependents[current] {
+			if !affected[dependent] {
+				affected[dependent] = true
+				queue = append(queue, dependent)
+			}
+		}
+	}
+
+	// Kahn's algorithm on the affected components, the dependencies come first
+	inDegree := make(map[string]int)
+	for name := range affected {
+		for _, edge := range graph[name] {
+			if affected[edge.Dependency] {
+				inDegree[name]++
+			}
+		}
+	}
+
+	order := make([]string, 0, len(affected))
+	ready := []string{start}
+	if inDegree[start] > 0 {
+		return nil, fmt.Errorf("dependency cycle involving component %s", start)
+	}
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		current := ready[0]
+		ready = ready[1:]
+		order = append(order, current)
+
+		for _, dependent := range dependents[current] {
+			if !affected[dependent] {
+				continue
+			}
+			for _, edge := range graph[dependent] {
+				if edge.Dependency == current {
+					inDegree[dependent]--
+				}
+			}
+			if inDegree[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(order) != len(affected) {
+		cyclic := make([]string, 0)
+		for name := range affected {
+			if !slices.Contains(order, name) {
+				cyclic = append(cyclic, name)
+			}
+		}
+		sort.Strings(cyclic)
+		return nil, fmt.Errorf("dependency cycle between components: %s", strings.Join(cyclic, ", "))
+	}
+
+	steps := make([]releaseStep, 0, len(order))
+	for _, name := range order {
+		step := releaseStep{Component: name, Previous: previous, Next: next}
+		if name != start {
+			prefix := componentPrefix(name, config.Components[name])
+			tags, err := getGitTagsWithPrefix(prefix)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch git tags of %s: %v", name, err)
+			}
+			step.Previous = version.Latest(tags)
+			step.Previous.Prefix = prefix
+			step.Next = step.Previous.Bump("patch")
+			// Like a single release, an untagged component starts with the initial version
+			if len(tags) == 0 {
+				step.Next, _, err = initialVersionOf(prefix, os.DirFS(componentDir(config.Components[name])))
+				if err != nil {
+					return nil, fmt.Errorf("component %s: %v", name, err)
+				}
+			}
+		}
+
+		for _, edge := range graph[name] {
+			if affected[edge.Dependency] {
+				step.Edges = append(step.Edges, edge)
+			}
+		}
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+// printCascadePlan prints the planned releases and the updated requirements
+func printCascadePlan(steps []releaseStep) {
+	fmt.Println("Release plan:")
+	for _, step := range steps {
+		requirements := make([]string, 0)
+		for _, edge := range step.Edges {
+			requirements = append(requirements, fmt.Sprintf("%s (%s)", edge.Dependency, edge.Kind))
+		}
+
+		line := fmt.Sprintf("  %s: %s -> %s", step.Component, step.Previous, step.Next)
+		if len(requirements) > 0 {
+			line += ", requires " + strings.Join(requirements, ", ")
+		}
+		fmt.Println(line)
+	}
+}
+
+// executeCascade writes the versions and the requirements of all steps,
+// creates a single commit and tags it for every component.
+// When something fails, the state before the cascade is restored (see rollbackCascade).
+func executeCascade(steps []releaseStep) error {
+	nextVersions := make(map[string]Tag)
+	for _, step := range steps {
+		nextVersions[step.Component] = step.Next
+	}
+
+	head, err := getCurrentGitHash()
+	if err != nil {
+		return fmt.Errorf("could not get current hash: %v", err)
+	}
+	newFiles := newGeneratedFiles(steps)
+
+	err = writeCascade(steps, nextVersions)
+	if err != nil {
+		return rollbackCascade(err, head, nil, newFiles)
+	}
+
+	names := make([]string, 0, len(steps))
+	for _, step := range steps {
+		names = append(names, step.Next.String())
+	}
+	err = commitAll("release " + strings.Join(names, ", "))
+	if err != nil {
+		return rollbackCascade(fmt.Errorf("failed to create commit: %v", err), head, nil, newFiles)
+	}
+
+	created := make([]string, 0, len(steps))
+	for _, step := range steps {
+		err = createTag(step.Next)
+		if err != nil {
+			return rollbackCascade(fmt.Errorf("failed to create tag %s: %v", step.Next, err), head, created, newFiles)
+		}
+		created = append(created, step.Next.String())
+	}
+
+	return nil
+}
+
+// newGeneratedFiles returns the version source files of the generate target within the components of the steps,
+// which do not exist yet. They are created by the cascade and untracked before its commit.
+func newGeneratedFiles(steps []releaseStep) []string {
+	result := make([]string, 0)
+	for _, step := range steps {
+		component := config.Components[step.Component]
+		if !slices.Contains(component.Write, "generate") {
+			continue
+		}
+		for _, file := range config.Generate {
+			path := filepath.Join(componentDir(component), targets.GeneratePath(file))
+			if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+				result = append(result, path)
+			}
+		}
+	}
+	return result
+}
+
+// rollbackCascade restores the state before a failed cascade: the checked out branch is reset to the previous HEAD,
+// which drops the release commit and the written changes, the created tags are deleted
+// and the newly generated files are removed. The cause is returned together with the failures of the rollback.
+func rollbackCascade(cause error, head string, createdTags []string, newFiles []string) error {
+	failures := make([]string, 0)
+
+	err := repo.ResetHard(head)
+	if err != nil {
+		failures = append(failures, fmt.Sprintf("resetting to %s: %v", shortCommit(head), err))
+	}
+	for _, name := range createdTags {
+		err = deleteTag(name)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("deleting tag %s: %v", name, err))
+		}
+	}
+	for _, path := range newFiles {
+		err = os.Remove(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			failures = append(failures, fmt.Sprintf("removing %s: %v", path, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%v (reverting failed too: %s)", cause, strings.Join(failures, ", "))
+	}
+	return cause
+}
+
+func writeCascade(steps []releaseStep, nextVersions map[string]Tag) error {
+	defer os.Chdir(rootDir)
+
+	for _, step := range steps {
+		component := config.Components[step.Component]
+		err := os.Chdir(componentDir(component))
+		if err != nil {
+			return err
+		}
+
+		for _, target := range component.Write {
+			err = writeVersionToTarget(target, step.Next, targetOptions())
+			if err != nil {
+				return fmt.Errorf("component %s: %v", step.Component, err)
+			}
+		}
+
+		for _, edge := range step.Edges {
+			err = writeDependencyRequirement(edge, nextVersions[edge.Dependency])
+			if err != nil {
+				return fmt.Errorf("component %s: failed to update requirement on %s: %v", step.Component, edge.Dependency, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeDependencyRequirement updates the required version of a dependency in the manifest of the current directory.
+// Path dependencies of pubspec.yaml have no version, so nothing is written for them.
+func writeDependencyRequirement(edge dependencyEdge, tag Tag) error {
+	var path string
+	var update func(content string) string
+
+	switch edge.Kind {
+	case "npm":
+		path = "package.json"
+		update = func(content string) string {
+			return utils.UpdateJsonDependencyVersion(content, edge.Name, tag.Version())
+		}
+	case "cargo":
+		path = "Cargo.toml"
+		update = func(content string) string {
+			return utils.UpdateCargoDependencyVersion(content, edge.Name, tag.Version())
+		}
+	case "go":
+		path = "go.mod"
+		update = func(content string) string {
+			return utils.UpdateGoModRequire(content, edge.Name, "v"+tag.Version())
+		}
+	default:
+		return nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(update(string(content))), 0644)
+}
+
+// releaseCascade releases the component with the new tag and all components depending on it
+func releaseCascade(component string, latestTag Tag, newTag Tag) error {
+	graph, err := buildDependencyGraph()
+	if err != nil {
+		return err
+	}
+
+	steps, err := planCascade(graph, component, latestTag, newTag)
+	if err != nil {
+		return err
+	}
+	printCascadePlan(steps)
+
+	if flagDry {
+		return nil
+	}
+
+	uncommittedChanges, err := hasUncommittedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to check, if uncommitted changes exist: %v", err)
+	}
+	if uncommittedChanges {
+		return fmt.Errorf("cannot use 'cascade' flag, because there are uncommitted changes")
+	}
+
+	err = executeCascade(steps)
+	if err != nil {
+		return err
+	}
+
+	for _, step := range steps {
+		fmt.Printf("Tagged %s -> %s\n", step.Previous, step.Next)
+	}
+	return nil
+}
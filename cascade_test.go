@@ -0,0 +1,156 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/MatthiasSchild/tagger/repository"
+	"github.com/MatthiasSchild/tagger/version"
+)
+
+// writeTestFiles writes the files (by slash separated path) into the directory
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBuildDependencyGraphSkipsDevDependencies(t *testing.T) {
+	rootDir = t.TempDir()
+	writeTestFiles(t, rootDir, map[string]string{
+		"a/package.json": `{"name": "a", "version": "1.0.0"}`,
+		"b/package.json": `{"name": "b", "dependencies": {"a": "^1.0.0"}}`,
+		"c/package.json": `{"name": "c", "devDependencies": {"a": "^1.0.0"}}`,
+		"d/Cargo.toml":   "[package]\nname = \"d\"\n\n[dev-dependencies]\na = { path = \"../a\" }\n",
+		"e/pubspec.yaml": "name: e\ndev_dependencies:\n  a:\n    path: ../a\n",
+		"f/pubspec.yaml": "name: f\ndependencies:\n  a:\n    path: ../a\n",
+		"g/Cargo.toml":   "[package]\nname = \"g\"\n\n[dependencies]\na = { path = \"../a\", version = \"1.0.0\" }\n",
+		"h/go.mod":       "module example.com/h\n\nreplace example.com/a => ../a\n",
+	})
+	config = Config{Components: map[string]ComponentConfig{}}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		config.Components[name] = ComponentConfig{Path: name}
+	}
+
+	graph, err := buildDependencyGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dependents := make([]string, 0)
+	for name, edges := range graph {
+		if len(edges) > 0 {
+			dependents = append(dependents, name)
+		}
+	}
+	slices.Sort(dependents)
+	if !slices.Equal(dependents, []string{"b", "f", "g", "h"}) {
+		t.Errorf("expected only runtime dependencies to cascade, got %v", dependents)
+	}
+}
+
+func TestExecuteCascadeRollback(t *testing.T) {
+	rootDir = t.TempDir()
+	t.Chdir(rootDir)
+	writeTestFiles(t, rootDir, map[string]string{
+		"a/package.json": `{"name": "a", "version": "0.1.0"}`,
+		"b/package.json": `{"name": "b", "version": "0.1.0", "dependencies": {"a": "0.1.0"}}`,
+	})
+	config = Config{
+		Components: map[string]ComponentConfig{
+			"a": {Path: "a", Prefix: "a/v", Write: []string{"npm", "generate"}},
+			"b": {Path: "b", Prefix: "b/v", Write: []string{"npm"}},
+		},
+		Generate: []GenerateFile{{Language: "go"}},
+	}
+
+	memory := repository.NewMemory()
+	head := memory.Commit("feat: something")
+	// The tag of b already exists, so tagging fails after the commit and the tag of a
+	if err := memory.CreateTag("b/v0.1.1", "HEAD", ""); err != nil {
+		t.Fatal(err)
+	}
+	repo = memory
+
+	next := func(prefix string, value string) Tag {
+		tag, err := version.Parse(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag.Prefix = prefix
+		return tag
+	}
+	steps := []releaseStep{
+		{Component: "a", Next: next("a/v", "v0.2.0")},
+		{Component: "b", Next: next("b/v", "v0.1.1"), Edges: []dependencyEdge{{Dependency: "a", Kind: "npm", Name: "a"}}},
+	}
+
+	err := executeCascade(steps)
+	if err == nil {
+		t.Fatal("expected the cascade to fail")
+	}
+	if current, _ := memory.Resolve("HEAD"); current != head {
+		t.Errorf("expected the release commit to be dropped")
+	}
+	if tags, _ := memory.Tags(); !slices.Equal(tags, []string{"b/v0.1.1"}) {
+		t.Errorf("expected the created tags to be deleted, got %v", tags)
+	}
+	if _, err := os.Stat(filepath.Join(rootDir, "a", "version.go")); !os.IsNotExist(err) {
+		t.Errorf("expected the generated file to be removed, got %v", err)
+	}
+}
+
+func TestPlanCascadeUntaggedDependent(t *testing.T) {
+	rootDir = t.TempDir()
+	writeTestFiles(t, rootDir, map[string]string{
+		"b/package.json": `{"name": "b", "version": "0.5.0"}`,
+	})
+	config = Config{Components: map[string]ComponentConfig{
+		"a": {Path: "a", Prefix: "a/v"},
+		"b": {Path: "b", Prefix: "b/v"},
+		"c": {Path: "c", Prefix: "c/v"},
+	}}
+	memory := repository.NewMemory()
+	_ = memory.CreateTag("a/v1.2.0", "HEAD", "")
+	_ = memory.CreateTag("c/v2.0.0", "HEAD", "")
+	repo = memory
+
+	graph := map[string][]dependencyEdge{
+		"b": {{Dependency: "a", Kind: "npm", Name: "a"}},
+		"c": {{Dependency: "a", Kind: "npm", Name: "a"}},
+	}
+	previous := Tag{Major: 1, Minor: 2, Prefix: "a/v"}
+	next := Tag{Major: 1, Minor: 2, Patch: 1, Prefix: "a/v"}
+
+	plan := func() []string {
+		t.Helper()
+		steps, err := planCascade(graph, "a", previous, next)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := make([]string, 0, len(steps))
+		for _, step := range steps {
+			result = append(result, step.Next.String())
+		}
+		return result
+	}
+
+	// The untagged component starts with the initial version instead of v0.0.1
+	if releases := plan(); !slices.Equal(releases, []string{"a/v1.2.1", "b/v0.1.0", "c/v2.0.1"}) {
+		t.Errorf("unexpected releases %v", releases)
+	}
+
+	// The version file of a target is read from the directory of the component
+	config.Initial = "npm"
+	if releases := plan(); !slices.Equal(releases, []string{"a/v1.2.1", "b/v0.5.0", "c/v2.0.1"}) {
+		t.Errorf("unexpected releases with the initial version of npm %v", releases)
+	}
+}
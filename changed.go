@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// changedComponent is a component with commits since its latest tag
+type changedComponent struct {
+	Name string
+	// LatestTag is the name of the latest tag, empty when the component is not tagged yet
+	LatestTag string
+	Commits   int
+}
+
+// changedComponents returns the components of the config file sorted by name,
+// which have commits touching their path since their latest tag
+func changedComponents() ([]changedComponent, error) {
+	names := make([]string, 0, len(config.Components))
+	for name := range config.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]changedComponent, 0)
+	for _, name := range names {
+		component := config.Components[name]
+		// The tag name is used instead of the version, since it can contain an addition (e.g. api/v1.2.3-abcdef)
+		_, latestName, err := getLatestGitTag(componentPrefix(name, component))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch git tags: %v", err)
+		}
+
+		commits, err := countCommitsSince(latestName, component.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count commits of %s: %v", name, err)
+		}
+		if commits > 0 {
+			result = append(result, changedComponent{Name: name, LatestTag: latestName, Commits: commits})
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/MatthiasSchild/tagger/repository"
+)
+
+func TestChangedComponents(t *testing.T) {
+	memory := repository.NewMemory()
+	memory.Commit("feat: api and web", "api/main.go", "web/index.ts")
+	// Tags created with --hash contain the commit, so their name differs from the version
+	if err := memory.CreateTag("api/v1.2.3-abcdef", "HEAD", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := memory.CreateTag("web/v0.1.0", "HEAD", ""); err != nil {
+		t.Fatal(err)
+	}
+	memory.Commit("fix: api", "api/handler.go")
+	memory.Commit("fix: api again", "api/handler.go")
+	repo = memory
+	config = Config{Components: map[string]ComponentConfig{
+		"api":  {Path: "api"},
+		"web":  {Path: "web"},
+		"docs": {Path: "docs", Prefix: "docs-"},
+	}}
+
+	changed, err := changedComponents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 1 || changed[0] != (changedComponent{Name: "api", LatestTag: "api/v1.2.3-abcdef", Commits: 2}) {
+		t.Errorf("expected api with 2 commits since its hashed tag, got %+v", changed)
+	}
+
+	memory.Commit("docs: readme", "docs/README.md")
+	changed, err = changedComponents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 2 || changed[1] != (changedComponent{Name: "docs", Commits: 1}) {
+		t.Errorf("expected the untagged docs with all their commits, got %+v", changed)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Exit codes of the check command, combined when multiple checks fail
+const (
+	checkExitLatestMismatch = 2
+	checkExitHeadMismatch   = 4
+	checkExitFilesDisagree  = 8
+)
+
+// checkResult is the outcome of checking the version file of a target
+type checkResult struct {
+	Target string
+	// Version is nil, when the version could not be read
+	Version *Tag
+	Status  string
+}
+
+// checkVersions compares the versions of the targets with the latest tag, the tag of HEAD and with each other.
+// The latest and the HEAD tag are nil, when they do not exist. When skipUnreadable is set (e.g. for detected
+// targets), targets without a readable version are reported but not checked, otherwise they fail the check.
+// The exit code combines the checkExit constants of the failed checks.
+func checkVersions(checkTargets []string, read func(target string) (Tag, error), latest *Tag, head *Tag, skipUnreadable bool) ([]checkResult, int, error) {
+	results := make([]checkResult, 0, len(checkTargets))
+	exitCode := 0
+	var firstVersion *Tag
+
+	for _, target := range checkTargets {
+		version, err := read(target)
+		if err != nil {
+			if !skipUnreadable {
+				return nil, 0, fmt.Errorf("failed to read version of %s: %v", target, err)
+			}
+			results = append(results, checkResult{Target: target, Status: "skipped: no version"})
+			continue
+		}
+
+		mismatches := make([]string, 0)
+		if latest != nil && !version.Equals(*latest) {
+			mismatches = append(mismatches, "latest tag")
+			exitCode |= checkExitLatestMismatch
+		}
+		if head != nil && !version.Equals(*head) {
+			mismatches = append(mismatches, "head tag")
+			exitCode |= checkExitHeadMismatch
+		}
+		if firstVersion == nil {
+			firstVersion = &version
+		} else if !version.Equals(*firstVersion) {
+			mismatches = append(mismatches, "other files")
+			exitCode |= checkExitFilesDisagree
+		}
+
+		status := "ok"
+		if len(mismatches) > 0 {
+			status = "mismatch: " + strings.Join(mismatches, ", ")
+		}
+		results = append(results, checkResult{Target: target, Version: &version, Status: status})
+	}
+
+	return results, exitCode, nil
+}
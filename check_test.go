@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/MatthiasSchild/tagger/targets"
+	"github.com/MatthiasSchild/tagger/version"
+)
+
+func mustParse(t *testing.T, value string) *Tag {
+	t.Helper()
+	tag, err := version.Parse(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &tag
+}
+
+func TestCheckVersions(t *testing.T) {
+	files := map[string]string{"npm": "v1.2.3", "helm": "v1.2.3", "cargo": "v1.3.0"}
+	read := func(target string) (Tag, error) {
+		value, ok := files[target]
+		if !ok {
+			return Tag{}, fmt.Errorf("no version")
+		}
+		return version.Parse(value)
+	}
+
+	tests := []struct {
+		name     string
+		targets  []string
+		latest   *Tag
+		head     *Tag
+		exitCode int
+	}{
+		{"agree", []string{"npm", "helm"}, mustParse(t, "v1.2.3"), mustParse(t, "v1.2.3"), 0},
+		{"untagged", []string{"npm"}, nil, nil, 0},
+		{"latest differs", []string{"npm"}, mustParse(t, "v1.2.4"), nil, checkExitLatestMismatch},
+		{"head differs", []string{"npm"}, mustParse(t, "v1.2.3"), mustParse(t, "v1.2.2"), checkExitHeadMismatch},
+		{"files differ", []string{"npm", "cargo"}, mustParse(t, "v1.2.3"), nil, checkExitLatestMismatch | checkExitFilesDisagree},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, exitCode, err := checkVersions(test.targets, read, test.latest, test.head, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if exitCode != test.exitCode {
+				t.Errorf("expected exit code %d, got %d", test.exitCode, exitCode)
+			}
+		})
+	}
+}
+
+func TestCheckVersionsWithoutVersion(t *testing.T) {
+	read := func(target string) (Tag, error) {
+		if target == "xcode" {
+			return Tag{}, fmt.Errorf("no MARKETING_VERSION found")
+		}
+		return version.Parse("v1.0.0")
+	}
+
+	results, exitCode, err := checkVersions([]string{"flutter", "xcode"}, read, mustParse(t, "v1.0.0"), nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exitCode != 0 {
+		t.Errorf("expected a skipped target not to fail the check, got exit code %d", exitCode)
+	}
+	if results[1].Version != nil || results[1].Status != "skipped: no version" {
+		t.Errorf("expected xcode to be skipped, got %+v", results[1])
+	}
+
+	_, _, err = checkVersions([]string{"flutter", "xcode"}, read, nil, nil, false)
+	if err == nil {
+		t.Error("expected a configured target without version to fail")
+	}
+}
+
+func TestCheckVersionsChart(t *testing.T) {
+	// The chart version is bumped independently of the tags, only the appVersion follows them
+	chartFiles := fstest.MapFS{
+		"Chart.yaml": {Data: []byte("apiVersion: v2\nname: app\nversion: 0.7.4\nappVersion: \"v1.2.3\"\n")},
+	}
+	read := func(target string) (Tag, error) {
+		return targets.Readers[target](chartFiles)
+	}
+
+	results, exitCode, err := checkVersions([]string{"helm"}, read, mustParse(t, "v1.2.3"), mustParse(t, "v1.2.3"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exitCode != 0 {
+		t.Errorf("expected the appVersion to match the tags, got exit code %d and %+v", exitCode, results[0])
+	}
+
+	_, exitCode, err = checkVersions([]string{"helm"}, read, mustParse(t, "v1.2.4"), nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exitCode != checkExitLatestMismatch {
+		t.Errorf("expected an outdated appVersion to mismatch, got exit code %d", exitCode)
+	}
+}
@@ -1,19 +1,24 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"regexp"
+	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"text/tabwriter"
 	"time"
 
+	"github.com/MatthiasSchild/tagger/journal"
+	"github.com/MatthiasSchild/tagger/release"
+	"github.com/MatthiasSchild/tagger/repository"
+	"github.com/MatthiasSchild/tagger/targets"
+	"github.com/MatthiasSchild/tagger/version"
 	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
 )
 
-var (
-	versionRegex = regexp.MustCompile(`^v?(\d+)\\.(\d+)\\.(\d+)([+-]([a-zA-Z0-9]+))?$`)
-)
-
 const rootCmdDescription = `---------------------------------
 Tagger for creating a new git tag
 ---------------------------------
@@ -39,6 +44,65 @@ The time will be 1577867400 % (60 * 60 * 24)
   = 30600
 so the version will result in v1.18262.30600
 
+First release:
+When no version tags exist, the initial version is tagged as it is (default v0.1.0).
+It can be set with --initial or "initial" in the config file, either as version (e.g. v1.0.0)
+or as a target, whose version file is read (e.g. --initial=npm uses the version of the package.json).
+
+Pre-flight checks:
+Before releasing, the rules enabled in the config file are checked and all failures are reported together.
+Each rule can be skipped with --force-<rule> (e.g. --force-behind).
+branch: the branch must match one of the allowed branches (e.g. main, release/*)
+detached: HEAD must not be detached
+behind: HEAD must not be behind its upstream (compared with the last fetched state)
+tagged: HEAD must not be tagged with a version yet
+unchanged: there must be commits since the last tag (within the component with --component)
+
+preflight:
+  branches:
+    - main
+    - release/*
+  rules:
+    - detached
+    - behind
+    - tagged
+    - unchanged
+
+Hooks:
+Commands of the config file run at points of the release, a failing command aborts it.
+pre-bump: before anything is changed
+post-write: after the version was written (only with --write), changed files are part of the release commit
+pre-tag: before the tag is created, the release commit is dropped when it fails
+post-tag: after the tag was created
+The version data is passed as environment variables: TAGGER_TAG (v1.2.3), TAGGER_VERSION (1.2.3),
+TAGGER_MAJOR, TAGGER_MINOR, TAGGER_PATCH, TAGGER_PREFIX, TAGGER_PREVIOUS (v1.2.2), TAGGER_PREVIOUS_VERSION,
+TAGGER_COMPONENT, TAGGER_ROOT (the directory tagger was started in) and TAGGER_HOOK.
+
+hooks:
+  pre-bump:
+    - npm test
+  post-write:
+    - npm install --package-lock-only
+
+Tagging another commit:
+With --commit <revision> (e.g. a commit validated by CI), the commit is tagged instead of HEAD.
+The previous version is the latest tag of its ancestors and version files are read from its files.
+Tagger refuses, when the next version is already tagged (e.g. on a later commit). --write is not allowed.
+
+Floating aliases:
+With --aliases (or "aliases: lightweight" or "aliases: annotated" in the config file), the tags of the
+major and minor line (e.g. v1 and v1.2 for v1.2.3) are moved to the new version, as GitHub Actions use them.
+The flag wins over the config file: --aliases=annotated creates annotated aliases, --aliases=none moves none.
+An alias is only moved, when the new version is the highest of its line, so a fix of v1.2 after v1.3.0
+only moves v1.2. Versions with a minus addition (e.g. --hash) move no aliases and "tagger undo" does not move them back.
+The aliases are no versions, so they are ignored when computing versions and by "tagger list".
+Moved aliases have to be pushed with force (git push --force origin v1 v1.2).
+
+Remote tags:
+Shallow clones (e.g. on CI runners) often have no tags. With --remote origin, the tags of the remote
+are listed with "git ls-remote" and combined with the local ones, nothing is fetched.
+Tags only existing locally or pointing at different commits are reported as warnings.
+
 Writing the new version into file:
 With the --write flag, you can tell tagger to write the new version into a file.
 Tagger will check, if any uncommitted changes are open.
@@ -55,6 +119,83 @@ You have the following targets as options:
 	But here, tagger tries to increment the additional part
 --write=cargo for writing the version into the Cargo.toml
   For this option, the version will have the format "major.minor.patch"
+--write=helm for writing the version into the Chart.yaml
+	For this option, the version will have the format "major.minor.patch"
+	The chart version gets a patch bump independently from the tag,
+	use --chart-bump=major|minor to bump another part or --chart-bump=tag to set it to the tag
+	With --app-version the appVersion is set to the new tag as well (including prerelease and build)
+	The versions of local subcharts (file://) are updated in Chart.yaml and Chart.lock
+--write=dotnet for writing the version into the Directory.Build.props and all csproj files
+	<Version>, <VersionPrefix> and <VersionSuffix> get the format "major.minor.patch(-suffix)"
+	<AssemblyVersion> and <FileVersion> get the format "major.minor.patch.revision"
+	The revision is incremented, unless it is set with --build-number
+--write=xcode for writing the version into all project.pbxproj and Info.plist files
+	MARKETING_VERSION and CFBundleShortVersionString get the format "major.minor.patch"
+	CURRENT_PROJECT_VERSION and CFBundleVersion are incremented, unless it is set with --build-number
+	Values referencing build settings (e.g. $(MARKETING_VERSION)) are kept
+--write=generate for generating version source files configured in the config file (.tagger.yaml)
+	Built-in languages are go, ts, rust and dart, custom templates can be used as well
+--write=<name> for writing the version with a custom target from the config file (.tagger.yaml)
+
+Config file:
+The config file (.tagger.yaml) can be created with "tagger init".
+It can set the tag prefix (default "v"), the default strategy (major, minor, patch or datetime)
+and the targets used by "tagger check" and "tagger sync".
+The git binary is run by default. With "git: native" (or --git=native), the .git directory is read and
+written in-process instead, which is faster for many tags, but does not run git hooks.
+
+git: exec
+prefix: v
+strategy: patch
+write:
+  - npm
+  - cargo
+
+Monorepo components:
+Independently versioned components have an own path, tag prefix (default "<name>/v") and targets.
+With --component, only this component is tagged and its targets are written relative to its path.
+"tagger changed" lists the components with commits touching their path since their last tag.
+With --cascade, all components depending on the released one get a patch release as well
+(or the initial version, when they have no tags yet).
+Their requirements (package.json dependencies, Cargo path dependencies with version and go.mod requires
+of replaced modules) are updated, everything is committed once and every component is tagged.
+The planned releases are printed first, with --dry only the plan is shown.
+
+components:
+  api:
+    path: services/api
+    prefix: api/v
+    write:
+      - npm
+
+Custom targets:
+A custom target lists file globs with a regular expression and a replacement template.
+The template can use the tag fields, e.g. {{.Major}}.{{.Minor}}.{{.Patch}} or {{.String}} for v1.2.3.
+Groups of the pattern can be referenced in the replacement with ${1}.
+Every matched file must contain the pattern, otherwise nothing will be written.
+The names of the built-in targets (npm, helm, generate, ...) cannot be used.
+
+targets:
+  docs:
+    files:
+      - glob: "README.md"
+        pattern: 'tagger@v[0-9.]+'
+        replace: 'tagger@{{.String}}'
+      - glob: "Dockerfile"
+        pattern: 'ARG VERSION=.*'
+        replace: 'ARG VERSION={{.Major}}.{{.Minor}}.{{.Patch}}'
+
+Generated version files:
+The templates get the fields .Version (e.g. v1.2.3), .Commit, .Package and .Tag.
+The commit is the one the release is based on. The package is set with "package", otherwise it is
+the name of the directory (e.g. mytool for cmd/my-tool/version.go) or main in the root directory.
+
+generate:
+  - language: go
+    path: internal/version/version.go
+  - language: ts
+  - template: templates/version.txt.tmpl
+    path: VERSION
 `
 
 var RootCmd = &cobra.Command{
@@ -62,108 +203,112 @@ var RootCmd = &cobra.Command{
 	Short:        "Create a new git tag",
 	Long:         rootCmdDescription,
 	SilenceUsage: true,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		err := validateFlags()
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		var err error
+		rootDir, err = os.Getwd()
 		if err != nil {
 			return err
 		}
-
-		tags, err := getAllGitTags()
+		config, err = loadConfig(flagConfig)
 		if err != nil {
-			return fmt.Errorf("failed to fetch git tags: %s", err.Error())
+			return err
 		}
-
-		if len(tags) == 0 {
-			return fmt.Errorf("no tags found")
+		if config.Prefix != nil {
+			tagPrefix = *config.Prefix
 		}
-
-		latestTag := getLatestTag(tags)
-		newTag := latestTag.Clone()
-		if flagMajor {
-			newTag.Major++
-			newTag.Minor = 0
-			newTag.Patch = 0
-		} else if flagMinor {
-			newTag.Minor++
-			newTag.Patch = 0
-		} else if flagPatch {
-			newTag.Patch++
+		switch flagAliases {
+		case "", aliasesNone, release.AliasesLightweight, release.AliasesAnnotated:
+		default:
+			return fmt.Errorf("aliases must be one of: lightweight, annotated, none")
 		}
-
-		if flagDateTime {
-			now := time.Now().Unix()
-			newTag.Minor = int(now % (60 * 60 * 24))
-			newTag.Patch = int(now / (60 * 60 * 24))
+		if flagComponent != "" {
+			err = useComponent(flagComponent)
+			if err != nil {
+				return err
+			}
 		}
 
-		if flagHash != 0 {
-			hash, err := getCurrentGitHash()
+		backend := config.Git
+		if flagGit != "" {
+			backend = flagGit
+		}
+		repo, err = repository.Open(".", backend)
+		if err != nil {
+			return fmt.Errorf("failed to open git repository: %v", err)
+		}
+		localRepo = repo
+		if flagCommit != "" {
+			versionFiles, err = repo.Files(flagCommit)
 			if err != nil {
-				return fmt.Errorf("could not get current hash: %s", err.Error())
+				return fmt.Errorf("failed to read the files of %s: %v", flagCommit, err)
 			}
+		}
+		if flagRemote != "" {
+			repo = repository.WithRemoteTags(repo, flagRemote, func(message string) {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", message)
+			})
+		}
+		recordJournal()
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := validateFlags()
+		if err != nil {
+			return err
+		}
 
-			newTag.MinusAddition = hash[:flagHash]
+		options := releaseOptionsFromFlags()
+		var initialSource string
+		options.Initial, initialSource, err = initialVersionIfUntagged()
+		if err != nil {
+			return err
+		}
+		result, err := release.Plan(repo, options)
+		if err != nil {
+			return err
+		}
+		if result.Initial {
+			fmt.Printf("No tags found, starting with the initial version %s (%s)\n", options.Initial, initialSource)
 		}
 
-		if !flagDry {
-			if flagWrite != "" {
-				uncommittedChanges, err := hasUncommittedChanges()
-				if err != nil {
-					return fmt.Errorf("failed to check, if uncommitted changes exist: %v", err)
-				}
-				if uncommittedChanges {
-					return fmt.Errorf("cannot use 'write' flag, because there are uncommitted changes")
-				}
+		var latestTag *Tag
+		if !result.Initial {
+			latestTag = &result.Previous
+		}
+		err = runPreflight(latestTag)
+		if err != nil {
+			return err
+		}
 
-				switch flagWrite {
-				case "npm":
-					err = writeVersionToPackageJson(newTag)
-					if err != nil {
-						return fmt.Errorf("failed to write package.json: %v", err)
-					}
-					err = commitAll(newTag.String())
-					if err != nil {
-						return fmt.Errorf("failed to create commit: %v", err)
-					}
-				case "flutter":
-					err = writeVersionToPubspecYaml(newTag, false)
-					if err != nil {
-						return fmt.Errorf("failed to write pubspec.yaml: %v", err)
-					}
-					err = commitAll(newTag.String())
-					if err != nil {
-						return fmt.Errorf("failed to create commit: %v", err)
-					}
-				case "flutter+":
-					err = writeVersionToPubspecYaml(newTag, true)
-					if err != nil {
-						return fmt.Errorf("failed to write pubspec.yaml: %v", err)
-					}
-					err = commitAll(newTag.String())
-					if err != nil {
-						return fmt.Errorf("failed to create commit: %v", err)
-					}
-				case "cargo":
-					err = writeVersionToCargoToml(newTag)
-					if err != nil {
-						return fmt.Errorf("failed to write Cargo.toml: %v", err)
-					}
-					err = commitAll(newTag.String())
-					if err != nil {
-						return fmt.Errorf("failed to create commit: %v", err)
-					}
-				default:
-					return fmt.Errorf("unknown write option: %s", flagWrite)
+		if flagCascade {
+			return releaseCascade(flagComponent, result.Previous, result.Next)
+		}
+
+		if options.Dry {
+			for _, point := range hookPoints {
+				for _, command := range config.Hooks[point] {
+					fmt.Printf("Would run %s hook: %s\n", point, command)
 				}
 			}
-
-			err = createTag(newTag)
+		} else {
+			err = release.Execute(repo, result, options)
 			if err != nil {
-				return fmt.Errorf("failed to create tag: %s", err.Error())
+				return err
 			}
 		}
 
-		fmt.Printf("Tagged %s -> %s\n", latestTag, newTag)
+		if result.Initial {
+			fmt.Printf("Tagged %s\n", result.Next)
+		} else {
+			fmt.Printf("Tagged %s -> %s\n", result.Previous, result.Next)
+		}
+		if len(result.Aliases) > 0 {
+			if options.Dry {
+				fmt.Printf("Would move aliases %s\n", strings.Join(result.Aliases, ", "))
+			} else {
+				fmt.Printf("Moved aliases %s\n", strings.Join(result.Aliases, ", "))
+			}
+		}
 		return nil
 	},
 }
@@ -185,7 +330,7 @@ var TagCmd = &cobra.Command{
 			prompt := promptui.Prompt{
 				Label: "New version",
 				Validate: func(s string) error {
-					if !versionRegex.MatchString(s) {
+					if _, err := version.Parse(s); err != nil {
 						return fmt.Errorf("the version must be in the format v1.2.3")
 					}
 					return nil
@@ -200,20 +345,12 @@ var TagCmd = &cobra.Command{
 			userInput = result
 		}
 
-		groups := versionRegex.FindStringSubmatch(userInput)
-
-		if groups == nil {
+		parsed, err := version.Parse(userInput)
+		if err != nil {
 			return fmt.Errorf("the version must be in the format v1.2.3")
 		}
-
-		major, _ := strconv.Atoi(groups[1])
-		minor, _ := strconv.Atoi(groups[2])
-		patch, _ := strconv.Atoi(groups[3])
-		newTag := Tag{
-			Major: major,
-			Minor: minor,
-			Patch: patch,
-		}
+		newTag := parsed.Clone()
+		newTag.Prefix = tagPrefix
 
 		tags, err := getAllGitTags()
 		if err != nil {
@@ -248,7 +385,12 @@ var ListCmd = &cobra.Command{
 		}
 
 		if len(tags) == 0 {
-			return fmt.Errorf("no tags found")
+			initial, source, err := initialVersion()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("No tags found, the first release will be %s (%s)\n", initial, source)
+			return nil
 		}
 
 		for _, tag := range tags {
@@ -265,10 +407,11 @@ var NpmCmd = &cobra.Command{
 	Long:         "Read the version from the package.json file and tag the current commit this version",
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		newTag, err := readVersionFromPackageJson()
+		newTag, err := targets.ReadPackageJson(versionFiles)
 		if err != nil {
 			return err
 		}
+		newTag.Prefix = tagPrefix
 
 		tags, err := getAllGitTags()
 		if err != nil {
@@ -297,10 +440,11 @@ var FlutterCmd = &cobra.Command{
 	Long:         "Read the version from the pubspec.yaml file and tag the current commit this version",
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		newTag, build, err := readVersionFromPubspecYaml()
+		newTag, build, err := targets.ReadPubspecYaml(versionFiles)
 		if err != nil {
 			return err
 		}
+		newTag.Prefix = tagPrefix
 
 		tags, err := getAllGitTags()
 		if err != nil {
@@ -316,6 +460,9 @@ var FlutterCmd = &cobra.Command{
 		}
 
 		if flagBuild {
+			if flagCommit != "" {
+				return fmt.Errorf("cannot use 'build' flag with --commit, because the build number is committed")
+			}
 			newTag.PlusAddition = strconv.Itoa(build + 1)
 
 			if !flagDry {
@@ -326,7 +473,7 @@ var FlutterCmd = &cobra.Command{
 				if uncommittedChanges {
 					return fmt.Errorf("cannot use 'build' flag, because there are uncommitted changes")
 				}
-				err = writeVersionAndBuildToPubspecYaml(newTag, build+1)
+				err = targets.WritePubspecYamlBuild(newTag, build+1)
 				if err != nil {
 					return fmt.Errorf("failed to update build number in pubspec.yaml: %v", err)
 				}
@@ -355,10 +502,11 @@ var CargoCmd = &cobra.Command{
 	Long:         "Read the version from the Cargo.toml file and tag the current commit this version",
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		newTag, err := readVersionFromCargoToml()
+		newTag, err := targets.ReadCargoToml(versionFiles)
 		if err != nil {
 			return err
 		}
+		newTag.Prefix = tagPrefix
 
 		tags, err := getAllGitTags()
 		if err != nil {
@@ -381,8 +529,701 @@ var CargoCmd = &cobra.Command{
 	},
 }
 
+var DotnetCmd = &cobra.Command{
+	Use:          "dotnet",
+	Short:        "Tag commit using the .NET project files",
+	Long:         "Read the version from the Directory.Build.props or csproj files and tag the current commit this version",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		newTag, err := targets.ReadDotnetProject(versionFiles)
+		if err != nil {
+			return err
+		}
+		newTag.Prefix = tagPrefix
+
+		tags, err := getAllGitTags()
+		if err != nil {
+			return fmt.Errorf("failed to fetch git tags for validation: %v", err)
+		}
+
+		for _, tag := range tags {
+			if tag.Equals(newTag) {
+				return fmt.Errorf("version tag already created: %s", tag.String())
+			}
+		}
+
+		err = createTag(newTag)
+		if err != nil {
+			return fmt.Errorf("failed to create tag: %v", err)
+		}
+
+		fmt.Printf("Tagged %s\n", newTag)
+		return nil
+	},
+}
+
+var XcodeCmd = &cobra.Command{
+	Use:          "xcode",
+	Short:        "Tag commit using the Xcode project",
+	Long:         "Read the MARKETING_VERSION from the project.pbxproj or Info.plist files and tag the current commit this version",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		newTag, err := targets.ReadXcodeProject(versionFiles)
+		if err != nil {
+			return err
+		}
+		newTag.Prefix = tagPrefix
+
+		tags, err := getAllGitTags()
+		if err != nil {
+			return fmt.Errorf("failed to fetch git tags for validation: %v", err)
+		}
+
+		for _, tag := range tags {
+			if tag.Equals(newTag) {
+				return fmt.Errorf("version tag already created: %s", tag.String())
+			}
+		}
+
+		err = createTag(newTag)
+		if err != nil {
+			return fmt.Errorf("failed to create tag: %v", err)
+		}
+
+		fmt.Printf("Tagged %s\n", newTag)
+		return nil
+	},
+}
+
+const syncCmdDescription = `Write the version of the latest tag (or the given version) into the version files.
+The targets of the config file are written, or the detected ones when none are configured.
+Build numbers of .NET and Xcode projects and the version of a helm chart are kept,
+the appVersion of a helm chart is set to the version.
+With --commit-changes, a commit with the version as message is created. A tag is never created.`
+
+var SyncCmd = &cobra.Command{
+	Use:          "sync [version]",
+	Short:        "Write the latest tag into the version files without tagging",
+	Long:         syncCmdDescription,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			return fmt.Errorf("usage: tagger sync [version]")
+		}
+
+		var tag Tag
+		if len(args) == 1 {
+			parsed, err := version.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("the version must be in the format v1.2.3")
+			}
+			tag = parsed.Clone()
+			tag.Prefix = tagPrefix
+		} else {
+			tags, err := getAllGitTags()
+			if err != nil {
+				return fmt.Errorf("failed to fetch git tags: %v", err)
+			}
+			if len(tags) == 0 {
+				return fmt.Errorf("no tags found")
+			}
+			tag = version.Latest(tags)
+		}
+
+		syncTargets := flagSyncTargets
+		if len(syncTargets) == 0 {
+			syncTargets = writableTargets()
+		}
+		if len(syncTargets) == 0 {
+			return fmt.Errorf("no version files found")
+		}
+
+		if flagDry {
+			fmt.Printf("Would sync %s into %s\n", tag, strings.Join(syncTargets, ", "))
+			return nil
+		}
+
+		if flagSyncCommitChanges {
+			uncommittedChanges, err := hasUncommittedChanges()
+			if err != nil {
+				return fmt.Errorf("failed to check, if uncommitted changes exist: %v", err)
+			}
+			if uncommittedChanges {
+				return fmt.Errorf("cannot use 'commit-changes' flag, because there are uncommitted changes")
+			}
+		}
+
+		// Generated files are untracked, until they are committed the first time
+		generatesNewFiles := false
+		if slices.Contains(syncTargets, "generate") {
+			for _, file := range config.Generate {
+				if _, err := os.Stat(targets.GeneratePath(file)); errors.Is(err, os.ErrNotExist) {
+					generatesNewFiles = true
+				}
+			}
+		}
+
+		// Syncing the same version again must not change the files, so build numbers and chart versions are kept
+		options := targetOptions()
+		options.BuildNumber = targets.KeepBuildNumber
+		options.ChartBump = "keep"
+		options.AppVersion = true
+		commit, err := versionCommit(tag)
+		if err != nil {
+			return fmt.Errorf("could not get the commit of %s: %v", tag, err)
+		}
+		options.Commit = commit
+		for _, target := range syncTargets {
+			err := writeVersionToTarget(target, tag, options)
+			if err != nil {
+				return err
+			}
+		}
+
+		if flagSyncCommitChanges {
+			changed, err := hasUncommittedChanges()
+			if err != nil {
+				return fmt.Errorf("failed to check, if uncommitted changes exist: %v", err)
+			}
+			if !changed && !generatesNewFiles {
+				fmt.Printf("%s is already synced into %s\n", tag, strings.Join(syncTargets, ", "))
+				return nil
+			}
+			err = commitAll(tag.String())
+			if err != nil {
+				return fmt.Errorf("failed to create commit: %v", err)
+			}
+		}
+
+		fmt.Printf("Synced %s into %s\n", tag, strings.Join(syncTargets, ", "))
+		return nil
+	},
+}
+
+const initCmdDescription = `Scan the repository for version files (package.json, pubspec.yaml, Cargo.toml, go.mod, ...),
+compare their versions with the existing tags and create the config file (.tagger.yaml).
+For every detected file you will be asked, if tagger should write the version into it.
+Afterwards the tag prefix and the default strategy can be chosen.
+With --yes, all detected files with a version and the defaults are used without asking.`
+
+var InitCmd = &cobra.Command{
+	Use:          "init",
+	Short:        "Create the config file from the detected version files",
+	Long:         initCmdDescription,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := os.Stat(flagConfig); err == nil && !flagInitForce {
+			return fmt.Errorf("config file %s already exists, use --force to overwrite it", flagConfig)
+		}
+
+		tags, err := getAllGitTags()
+		if err != nil {
+			return fmt.Errorf("failed to fetch git tags: %v", err)
+		}
+		latestText := "-"
+		var latestTag Tag
+		if len(tags) > 0 {
+			latestTag = version.Latest(tags)
+			latestText = latestTag.String()
+		}
+
+		manifests := targets.DetectManifests(versionFiles)
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "FILE\tTARGET\tVERSION\tLATEST TAG\tSTATUS")
+		for _, manifest := range manifests {
+			version := manifest.Version
+			status := "ok"
+			if version == "" {
+				version = "-"
+				status = "no version"
+			} else if len(tags) > 0 && version != latestTag.Version() {
+				status = "differs from latest tag"
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", manifest.Path, manifest.Target, version, latestText, status)
+		}
+		err = writer.Flush()
+		if err != nil {
+			return err
+		}
+		if len(manifests) == 0 {
+			fmt.Println("No version files found")
+		}
+
+		newConfig, err := initConfig(manifests, askConfirm)
+		if err != nil {
+			return err
+		}
+
+		prefix := version.DefaultPrefix
+		strategy := "patch"
+		if !flagInitYes {
+			prefixPrompt := promptui.Prompt{
+				Label:     "Tag prefix",
+				Default:   prefix,
+				AllowEdit: true,
+			}
+			prefix, err = prefixPrompt.Run()
+			if err != nil {
+				return fmt.Errorf("prompt failed %v", err)
+			}
+
+			strategySelect := promptui.Select{
+				Label: "Default strategy",
+				Items: []string{"patch", "minor", "major", "datetime"},
+			}
+			_, strategy, err = strategySelect.Run()
+			if err != nil {
+				return fmt.Errorf("prompt failed %v", err)
+			}
+		}
+		newConfig.Prefix = &prefix
+		newConfig.Strategy = strategy
+
+		err = saveConfig(flagConfig, newConfig)
+		if err != nil {
+			return fmt.Errorf("failed to write config: %v", err)
+		}
+
+		fmt.Printf("Created %s\n", flagConfig)
+		return nil
+	},
+}
+
+// askConfirm asks a yes/no question, which is answered with the default when --yes is set
+func askConfirm(label string, defaultYes bool) (bool, error) {
+	if flagInitYes {
+		return defaultYes, nil
+	}
+
+	prompt := promptui.Prompt{
+		Label:     label,
+		IsConfirm: true,
+		Default:   "n",
+	}
+	if defaultYes {
+		prompt.Default = "y"
+	}
+	_, err := prompt.Run()
+	if errors.Is(err, promptui.ErrAbort) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("prompt failed %v", err)
+	}
+	return true, nil
+}
+
+var ChangedCmd = &cobra.Command{
+	Use:          "changed",
+	Short:        "List the components changed since their last tag",
+	Long:         "List the components of the config file, which have commits touching their path since their last tag",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(config.Components) == 0 {
+			return fmt.Errorf("no components configured")
+		}
+
+		changed, err := changedComponents()
+		if err != nil {
+			return err
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "COMPONENT\tLATEST TAG\tCOMMITS")
+		for _, component := range changed {
+			latestText := "-"
+			if component.LatestTag != "" {
+				latestText = component.LatestTag
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%d\n", component.Name, latestText, component.Commits)
+		}
+
+		return writer.Flush()
+	},
+}
+
+const checkCmdDescription = `Check that the version files agree with the latest tag, the tag on HEAD and with each other.
+The targets of the config file are checked, or the detected ones when none are configured.
+Nothing will be written or tagged.
+
+The exit code is a combination of:
+2: a version file disagrees with the latest tag
+4: a version file disagrees with the tag on HEAD
+8: the version files disagree with each other
+1 is returned when the check itself failed`
+
+var CheckCmd = &cobra.Command{
+	Use:          "check",
+	Short:        "Check that the version files agree with the tags",
+	Long:         checkCmdDescription,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		checkTargets := readableTargets()
+		if len(checkTargets) == 0 {
+			return fmt.Errorf("no version files found")
+		}
+
+		tags, err := getAllGitTags()
+		if err != nil {
+			return fmt.Errorf("failed to fetch git tags: %v", err)
+		}
+		headTags, err := getHeadGitTags()
+		if err != nil {
+			return fmt.Errorf("failed to fetch git tags of HEAD: %v", err)
+		}
+
+		latestText := "-"
+		var latestTag *Tag
+		if len(tags) > 0 {
+			latest := version.Latest(tags)
+			latestTag = &latest
+			latestText = latest.String()
+		}
+		headText := "-"
+		var headTag *Tag
+		if len(headTags) > 0 {
+			head := version.Latest(headTags)
+			headTag = &head
+			headText = head.String()
+		}
+
+		read := func(target string) (Tag, error) {
+			tag, err := targets.Readers[target](versionFiles)
+			tag.Prefix = tagPrefix
+			return tag, err
+		}
+		// Detected version files (e.g. an Info.plist only referencing build settings) may have no version
+		results, exitCode, err := checkVersions(checkTargets, read, latestTag, headTag, len(config.Write) == 0)
+		if err != nil {
+			return err
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "TARGET\tVERSION\tLATEST TAG\tHEAD TAG\tSTATUS")
+		for _, result := range results {
+			versionText := "-"
+			if result.Version != nil {
+				versionText = result.Version.String()
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", result.Target, versionText, latestText, headText, result.Status)
+		}
+		err = writer.Flush()
+		if err != nil {
+			return err
+		}
+
+		if exitCode != 0 {
+			return &ExitCodeError{Code: exitCode, Message: "version files do not agree with the tags"}
+		}
+		return nil
+	},
+}
+
+const undoCmdDescription = `Undo the last release of tagger: its tags are deleted and the release commit is dropped.
+Every run of tagger records the created commits and tags in a journal (.git/tagger/journal.json).
+With --remote, the tags are deleted on the remote as well.
+
+Undoing is refused, when the release commit has new commits on top, the commit was already pushed
+or (with --remote) the tags were already pushed. Use --force to undo anyway,
+but consumers might already use the released version.
+Uncommitted changes are never discarded and the release commit is only dropped on the branch it was created on.`
+
+var UndoCmd = &cobra.Command{
+	Use:          "undo",
+	Short:        "Undo the last release",
+	Long:         undoCmdDescription,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		j, err := loadJournal()
+		if err != nil {
+			return fmt.Errorf("failed to load the journal: %v", err)
+		}
+		entry, ok := j.Last()
+		if !ok {
+			return fmt.Errorf("nothing to undo")
+		}
+
+		// The tags of --remote are not deleted locally, so only the local tags are looked up
+		undo, err := journal.PlanUndo(localRepo, entry, flagRemote)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Undo of \"%s\" from %s:\n", entry.Command, entry.Time.Format(time.DateTime))
+		for _, name := range undo.RemoteTags {
+			fmt.Printf("  delete tag %s on %s\n", name, flagRemote)
+		}
+		for _, name := range undo.Tags {
+			fmt.Printf("  delete tag %s\n", name)
+		}
+		for _, name := range undo.Skipped {
+			fmt.Printf("  skip tag %s (deleted or moved since)\n", name)
+		}
+		if undo.Reset != "" {
+			fmt.Printf("  drop %d commit(s), reset to %s\n", len(entry.Commits), undo.Reset[:7])
+		}
+
+		if len(undo.Blockers) > 0 {
+			if !flagUndoForce {
+				return fmt.Errorf("refusing to undo (use --force to undo anyway):\n  %s", strings.Join(undo.Blockers, "\n  "))
+			}
+			for _, blocker := range undo.Blockers {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", blocker)
+			}
+		}
+
+		if flagDry {
+			return nil
+		}
+		if !flagUndoYes {
+			confirmed, err := askConfirm("Undo the release", false)
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				return fmt.Errorf("undo aborted")
+			}
+		}
+
+		err = undo.Execute(localRepo, entry, flagRemote)
+		if err != nil {
+			return err
+		}
+		err = j.Save()
+		if err != nil {
+			return fmt.Errorf("failed to save the journal: %v", err)
+		}
+
+		fmt.Println("Undone")
+		return nil
+	},
+}
+
+const deleteCmdDescription = `Delete the tags of a version (v1.2.3) or an inclusive range of versions (v1.2.0..v1.2.5).
+All tags of the versions are deleted, including the ones with an addition (e.g. v1.2.3-rc1),
+unless the version has an addition itself. With --remote, the tags are deleted on the remote as well.
+With --component, the tags with the prefix of the component are deleted (e.g. api/v1.2.3 for v1.2.3).
+
+With --retract, the retraction is recorded for the consumers as well:
+go.mod gets a retract directive (with --reason as rationale)
+and the sections of the versions in CHANGELOG.md get a "retracted" item.
+The changed files are not committed, a new release is needed to publish the retraction.`
+
+var DeleteCmd = &cobra.Command{
+	Use:          "delete <version|range>",
+	Short:        "Delete or retract version tags",
+	Long:         deleteCmdDescription,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("usage: tagger delete <version|range>")
+		}
+		versions, err := parseVersionRange(args[0], tagPrefix)
+		if err != nil {
+			return err
+		}
+
+		localNames, err := localRepo.Tags()
+		if err != nil {
+			return fmt.Errorf("failed to fetch git tags: %v", err)
+		}
+		localNames = filterTagNames(localNames, versions, tagPrefix)
+
+		remoteNames := make([]string, 0)
+		if flagRemote != "" {
+			remoteTags, err := repo.RemoteTags(flagRemote)
+			if err != nil {
+				return fmt.Errorf("failed to list the tags of %s: %v", flagRemote, err)
+			}
+			for name := range remoteTags {
+				remoteNames = append(remoteNames, name)
+			}
+			remoteNames = filterTagNames(remoteNames, versions, tagPrefix)
+		}
+
+		names := slices.Compact(slices.Sorted(slices.Values(append(slices.Clone(localNames), remoteNames...))))
+		if len(names) == 0 {
+			return fmt.Errorf("no tags found for %s", args[0])
+		}
+		if flagDeleteRetract && len(retractFiles()) == 0 {
+			return fmt.Errorf("no go.mod or CHANGELOG.md found to record the retraction")
+		}
+
+		for _, name := range remoteNames {
+			fmt.Printf("Delete tag %s on %s\n", name, flagRemote)
+		}
+		for _, name := range localNames {
+			fmt.Printf("Delete tag %s\n", name)
+		}
+		if flagDeleteRetract {
+			fmt.Printf("Retract %s in %s\n", versions, strings.Join(retractFiles(), ", "))
+		}
+
+		if flagDry {
+			return nil
+		}
+		if !flagDeleteYes {
+			confirmed, err := askConfirm("Delete the tags", false)
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				return fmt.Errorf("delete aborted")
+			}
+		}
+
+		for _, name := range remoteNames {
+			err = repo.DeleteRemoteTag(flagRemote, name)
+			if err != nil {
+				return fmt.Errorf("failed to delete tag %s on %s: %v", name, flagRemote, err)
+			}
+		}
+		for _, name := range localNames {
+			err = localRepo.DeleteTag(name)
+			if err != nil {
+				return fmt.Errorf("failed to delete tag %s: %v", name, err)
+			}
+		}
+
+		if flagDeleteRetract {
+			err = recordRetraction(versions, names, flagDeleteReason, tagPrefix)
+			if err != nil {
+				return err
+			}
+		}
+
+		fmt.Printf("Deleted %d tag(s)\n", len(names))
+		return nil
+	},
+}
+
+const backfillCmdDescription = `Create the missing tags of the versions a version file had in the past.
+The history of the version file of the target (e.g. --from npm for the package.json) is walked
+from the oldest commit and every version, which is not tagged yet, is tagged at the commit it first appeared in.
+Only the first parent history of HEAD is considered, so merged versions are tagged at the merge commit.
+With --dry, the tags are only printed.`
+
+var BackfillCmd = &cobra.Command{
+	Use:          "backfill",
+	Short:        "Tag the past versions of a version file",
+	Long:         backfillCmdDescription,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if flagBackfillFrom == "" {
+			return fmt.Errorf("--from is required, e.g. --from npm")
+		}
+
+		missing, err := findBackfillTags(repo, flagBackfillFrom, tagPrefix)
+		if err != nil {
+			return err
+		}
+		if len(missing) == 0 {
+			fmt.Println("All versions are tagged")
+			return nil
+		}
+
+		for _, backfill := range missing {
+			if flagDry {
+				fmt.Printf("Would tag %s at %s\n", backfill.Tag, backfill.Commit[:7])
+				continue
+			}
+			err = repo.CreateTag(backfill.Tag.String(), backfill.Commit, backfill.Tag.String())
+			if err != nil {
+				return fmt.Errorf("failed to create tag %s: %v", backfill.Tag, err)
+			}
+			fmt.Printf("Tagged %s at %s\n", backfill.Tag, backfill.Commit[:7])
+		}
+		return nil
+	},
+}
+
+const statusCmdDescription = `Show the release state of the repository: the branch and its upstream, uncommitted changes,
+the latest tag and the commits since, the next version with the bump suggested by the commit messages
+(conventional commits: "feat" is minor, "!" or "BREAKING CHANGE" is major, everything else patch),
+the versions of all detected version files and if HEAD is releasable.
+HEAD is releasable, when the pre-flight checks of the config file pass, it is not tagged yet
+and there are commits since the latest tag. With --json, the state is printed as JSON.`
+
+var StatusCmd = &cobra.Command{
+	Use:          "status",
+	Short:        "Show the release state of the repository",
+	Long:         statusCmdDescription,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report, err := buildStatus()
+		if err != nil {
+			return err
+		}
+		return printStatus(report, flagStatusJson)
+	},
+}
+
+const dockerTagsCmdDescription = `Print the image tags of a version, one per line, e.g. for "docker build -t".
+The version is passed as argument, by default it is the highest version tag of HEAD.
+
+For v1.2.3 the tags are 1.2.3, 1.2, 1 and latest. The aliases 1.2, 1 and latest are only printed,
+when the version is the highest of its line compared with the version tags of the repository,
+so a fix of an older line (e.g. 1.2.4 after 1.3.0) does not move 1 and latest.
+Prereleases (e.g. 1.3.0-rc1) only get their own tag and "+" is replaced with "_" (e.g. 1.2.3_4).
+Tags with the commit hash of --hash (e.g. 1.2.3-1fa342) are releases and get the aliases.
+
+The commit is the one of the version tag, HEAD when the version is not tagged yet.
+--sha: add the tag sha-<hash> with the short hash of the commit
+--image <name>: prefix the tags with the image (e.g. ghcr.io/org/app:1.2.3)
+--labels: print the OCI labels of the version and the commit instead`
+
+var DockerTagsCmd = &cobra.Command{
+	Use:          "docker-tags [version]",
+	Short:        "Print the container image tags of a version",
+	Long:         dockerTagsCmdDescription,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			return fmt.Errorf("usage: tagger docker-tags [version]")
+		}
+
+		var tag Tag
+		var err error
+		if len(args) == 1 {
+			tag, err = version.Parse(strings.TrimPrefix(args[0], tagPrefix))
+			if err != nil {
+				return err
+			}
+			tag.Prefix = tagPrefix
+		} else {
+			tag, err = headVersion()
+			if err != nil {
+				return err
+			}
+		}
+
+		commit, err := versionCommit(tag)
+		if err != nil {
+			return fmt.Errorf("could not get the commit of %s: %v", tag, err)
+		}
+		if flagDockerLabels {
+			for _, label := range ociLabels(tag, commit) {
+				fmt.Println(label)
+			}
+			return nil
+		}
+
+		names, err := repo.Tags()
+		if err != nil {
+			return fmt.Errorf("failed to fetch git tags: %v", err)
+		}
+		for _, name := range dockerTags(tag, version.NewIndex(names, tagPrefix), commit, flagDockerSha) {
+			if flagDockerImage != "" {
+				name = flagDockerImage + ":" + name
+			}
+			fmt.Println(name)
+		}
+		return nil
+	},
+}
+
 func init() {
-	RootCmd.AddCommand(TagCmd, ListCmd, NpmCmd, FlutterCmd, CargoCmd)
+	RootCmd.AddCommand(TagCmd, ListCmd, NpmCmd, FlutterCmd, CargoCmd, DotnetCmd, XcodeCmd, CheckCmd, SyncCmd, InitCmd, ChangedCmd, UndoCmd, DeleteCmd, BackfillCmd, StatusCmd, DockerTagsCmd)
 
 	RootCmd.Flags().BoolVar(&flagMajor, "major", false, "Increase major part")
 	RootCmd.Flags().BoolVar(&flagMinor, "minor", false, "Increase minor part")
@@ -390,7 +1231,53 @@ func init() {
 	RootCmd.Flags().BoolVar(&flagDateTime, "datetime", false, "Set minor and patch to date time")
 	RootCmd.Flags().IntVar(&flagHash, "hash", 0, "Add commit hash to end")
 	RootCmd.PersistentFlags().BoolVarP(&flagDry, "dry", "d", false, "Show new tag but don't apply")
+	RootCmd.PersistentFlags().StringVar(&flagConfig, "config", ".tagger.yaml", "Path of the config file")
+	RootCmd.PersistentFlags().StringVar(&flagGit, "git", "", "Git backend: exec (default) to run the git binary or native to read .git in-process")
+	RootCmd.PersistentFlags().StringVar(&flagRemote, "remote", "", "Combine the local tags with the tags of a remote (e.g. origin), without fetching them")
+	RootCmd.PersistentFlags().StringVar(&flagComponent, "component", "", "Component of the config to release")
 	RootCmd.Flags().StringVar(&flagWrite, "write", "", "Write the version into file (see help)")
+	RootCmd.Flags().BoolVar(&flagCascade, "cascade", false, "Release the components depending on the component as well")
+	RootCmd.Flags().StringVar(&flagInitial, "initial", "", "First version when no tags exist (default v0.1.0), or a target to read it from (e.g. npm)")
+	RootCmd.Flags().BoolVar(&flagForceBranch, "force-branch", false, "Skip the pre-flight check of the allowed branches")
+	RootCmd.Flags().BoolVar(&flagForceDetached, "force-detached", false, "Skip the pre-flight check for a detached HEAD")
+	RootCmd.Flags().BoolVar(&flagForceBehind, "force-behind", false, "Skip the pre-flight check, that HEAD is not behind its upstream")
+	RootCmd.Flags().BoolVar(&flagForceTagged, "force-tagged", false, "Skip the pre-flight check, that HEAD is not tagged yet")
+	RootCmd.Flags().BoolVar(&flagForceUnchanged, "force-unchanged", false, "Skip the pre-flight check for commits since the last tag")
+	RootCmd.Flags().StringVar(&flagChartBump, "chart-bump", "patch", "Strategy for the helm chart version: patch, minor, major or tag")
+	RootCmd.Flags().BoolVar(&flagAppVersion, "app-version", false, "Set the helm appVersion to the new tag")
+	RootCmd.Flags().IntVar(&flagBuildNumber, "build-number", targets.IncrementBuildNumber, "Build number for dotnet and xcode (default: increment)")
+	// --revision is the name of the build number before it was used by xcode as well
+	RootCmd.Flags().IntVar(&flagBuildNumber, "revision", targets.IncrementBuildNumber, "Revision for the four-part .NET versions (default: increment)")
+	_ = RootCmd.Flags().MarkDeprecated("revision", "use --build-number instead")
 
 	FlutterCmd.Flags().BoolVar(&flagBuild, "build", false, "Increase build number and tag with +build")
+
+	for _, command := range []*cobra.Command{RootCmd, TagCmd, NpmCmd, FlutterCmd, CargoCmd, DotnetCmd, XcodeCmd} {
+		command.Flags().StringVar(&flagCommit, "commit", "", "Tag the commit of the revision instead of HEAD, its version files are read")
+	}
+	for _, command := range []*cobra.Command{RootCmd, TagCmd, NpmCmd, FlutterCmd, CargoCmd, DotnetCmd, XcodeCmd} {
+		command.Flags().StringVar(&flagAliases, "aliases", "", "Move the alias tags of the major and minor line (e.g. v1 and v1.2) to the new version: lightweight, annotated or none (default: config)")
+		command.Flags().Lookup("aliases").NoOptDefVal = release.AliasesLightweight
+	}
+
+	InitCmd.Flags().BoolVarP(&flagInitYes, "yes", "y", false, "Use the detected files and the defaults without asking")
+	InitCmd.Flags().BoolVar(&flagInitForce, "force", false, "Overwrite an existing config file")
+
+	SyncCmd.Flags().StringSliceVar(&flagSyncTargets, "target", nil, "Targets to write (default: config or detected)")
+	SyncCmd.Flags().BoolVar(&flagSyncCommitChanges, "commit-changes", false, "Commit the written version files")
+
+	UndoCmd.Flags().BoolVar(&flagUndoForce, "force", false, "Undo, even when the release was pushed or has commits on top")
+	UndoCmd.Flags().BoolVarP(&flagUndoYes, "yes", "y", false, "Undo without asking for confirmation")
+
+	DeleteCmd.Flags().BoolVarP(&flagDeleteYes, "yes", "y", false, "Delete without asking for confirmation")
+	DeleteCmd.Flags().BoolVar(&flagDeleteRetract, "retract", false, "Record the retraction in go.mod and CHANGELOG.md")
+	DeleteCmd.Flags().StringVar(&flagDeleteReason, "reason", "", "Reason of the retraction")
+
+	BackfillCmd.Flags().StringVar(&flagBackfillFrom, "from", "", "Target whose version file history is read (npm, flutter, cargo, helm, dotnet or xcode)")
+
+	StatusCmd.Flags().BoolVar(&flagStatusJson, "json", false, "Print the state as JSON")
+
+	DockerTagsCmd.Flags().BoolVar(&flagDockerSha, "sha", false, "Add the tag sha-<hash> of HEAD")
+	DockerTagsCmd.Flags().StringVar(&flagDockerImage, "image", "", "Prefix the tags with the image name")
+	DockerTagsCmd.Flags().BoolVar(&flagDockerLabels, "labels", false, "Print the OCI labels instead of the tags")
 }
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/MatthiasSchild/tagger/release"
+	"github.com/MatthiasSchild/tagger/targets"
+	"gopkg.in/yaml.v3"
+)
+
+// Config contains the settings of the configuration file (.tagger.yaml)
+type Config struct {
+	Git      string                  `yaml:"git,omitempty"`
+	Prefix   *string                 `yaml:"prefix,omitempty"`
+	Strategy string                  `yaml:"strategy,omitempty"`
+	Initial  string                  `yaml:"initial,omitempty"`
+	Write    []string                `yaml:"write,omitempty"`
+	Targets  map[string]TargetConfig `yaml:"targets,omitempty"`
+	Generate []GenerateFile          `yaml:"generate,omitempty"`
+	Aliases  string                  `yaml:"aliases,omitempty"`
+
+	Preflight PreflightConfig     `yaml:"preflight,omitempty"`
+	Hooks     map[string][]string `yaml:"hooks,omitempty"`
+
+	Components map[string]ComponentConfig `yaml:"components,omitempty"`
+}
+
+// PreflightConfig contains the checks done before releasing.
+// The branch rule is enabled by the allowed branches (patterns like release/*),
+// the other rules (detached, behind, tagged, unchanged) are enabled by listing them.
+type PreflightConfig struct {
+	Branches []string `yaml:"branches,omitempty"`
+	Rules    []string `yaml:"rules,omitempty"`
+}
+
+// ComponentConfig describes an independently versioned component of a monorepo.
+// Its tags have an own prefix (default: "<name>/v", e.g. api/v1.2.3)
+// and its write targets are relative to its path.
+type ComponentConfig struct {
+	Path   string   `yaml:"path"`
+	Prefix string   `yaml:"prefix,omitempty"`
+	Write  []string `yaml:"write,omitempty"`
+}
+
+// TargetConfig describes a custom write target, see the targets package
+type TargetConfig = targets.CustomTarget
+
+// FileReplacement describes a replacement of a custom target, see the targets package
+type FileReplacement = targets.FileReplacement
+
+// GenerateFile describes a version source file of the generate target, see the targets package
+type GenerateFile = targets.GenerateFile
+
+var config Config
+
+// rootDir is the working directory tagger was started in, before switching to a component
+var rootDir string
+
+// loadConfig reads the configuration file.
+// A missing file results in an empty configuration.
+func loadConfig(path string) (Config, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Config{}, nil
+	}
+	if err != nil {
+		return Config{}, err
+	}
+
+	var result Config
+	err = yaml.Unmarshal(content, &result)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid config %s: %v", path, err)
+	}
+
+	switch result.Strategy {
+	case "", "major", "minor", "patch", "datetime":
+	default:
+		return Config{}, fmt.Errorf("strategy must be one of: major, minor, patch, datetime")
+	}
+
+	switch result.Aliases {
+	case "", release.AliasesLightweight, release.AliasesAnnotated:
+	default:
+		return Config{}, fmt.Errorf("aliases must be one of: lightweight, annotated")
+	}
+
+	for name, target := range result.Targets {
+		if slices.Contains(targets.BuiltIn, name) {
+			return Config{}, fmt.Errorf("target %s is a built-in target, use another name", name)
+		}
+		if len(target.Files) == 0 {
+			return Config{}, fmt.Errorf("target %s has no files", name)
+		}
+		for _, file := range target.Files {
+			if file.Glob == "" || file.Pattern == "" {
+				return Config{}, fmt.Errorf("target %s needs a glob and a pattern for every file", name)
+			}
+		}
+	}
+
+	for _, file := range result.Generate {
+		if file.Template == "" && file.Language == "" {
+			return Config{}, fmt.Errorf("generate needs a language or a template for every file")
+		}
+		if file.Template != "" && file.Path == "" {
+			return Config{}, fmt.Errorf("generate needs a path for the template %s", file.Template)
+		}
+		if file.Package != "" && !token.IsIdentifier(file.Package) {
+			return Config{}, fmt.Errorf("generate package %s is not a valid identifier", file.Package)
+		}
+	}
+
+	for _, rule := range result.Preflight.Rules {
+		if !slices.Contains(preflightRules, rule) {
+			return Config{}, fmt.Errorf("unknown preflight rule %s, use %s", rule, strings.Join(preflightRules, ", "))
+		}
+	}
+
+	for point := range result.Hooks {
+		if !slices.Contains(hookPoints, point) {
+			return Config{}, fmt.Errorf("unknown hook %s, use %s", point, strings.Join(hookPoints, ", "))
+		}
+	}
+
+	for name, component := range result.Components {
+		if component.Path == "" {
+			return Config{}, fmt.Errorf("component %s has no path", name)
+		}
+	}
+
+	return result, nil
+}
+
+// componentPrefix returns the tag prefix of a component
+func componentPrefix(name string, component ComponentConfig) string {
+	if component.Prefix != "" {
+		return component.Prefix
+	}
+	return name + "/v"
+}
+
+// useComponent switches to a component of the config:
+// its tag prefix and write targets are used and its path becomes the working directory.
+func useComponent(name string) error {
+	component, ok := config.Components[name]
+	if !ok {
+		return fmt.Errorf("unknown component: %s", name)
+	}
+
+	tagPrefix = componentPrefix(name, component)
+	config.Write = component.Write
+
+	err := os.Chdir(component.Path)
+	if err != nil {
+		return fmt.Errorf("failed to change to the path of component %s: %v", name, err)
+	}
+	return nil
+}
+
+// saveConfig writes the configuration file
+func saveConfig(path string, cfg Config) error {
+	content := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(content)
+	encoder.SetIndent(2)
+	err := encoder.Encode(cfg)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content.Bytes(), 0644)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".tagger.yaml")
+	content := `prefix: rel-
+strategy: minor
+write:
+  - npm
+  - docs
+targets:
+  docs:
+    files:
+      - glob: README.md
+        pattern: 'v[0-9.]+'
+        replace: '{{.String}}'
+generate:
+  - language: go
+    path: cmd/my-tool/version.go
+    package: main
+components:
+  api:
+    path: services/api
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Prefix == nil || *result.Prefix != "rel-" || result.Strategy != "minor" || len(result.Write) != 2 {
+		t.Errorf("unexpected config %+v", result)
+	}
+	if len(result.Targets["docs"].Files) != 1 || result.Generate[0].Package != "main" || result.Components["api"].Path != "services/api" {
+		t.Errorf("unexpected targets, generated files or components %+v", result)
+	}
+
+	result, err = loadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil || len(result.Write) != 0 {
+		t.Errorf("expected an empty config for a missing file, got %+v, %v", result, err)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	tests := map[string]string{
+		"strategy: huge": "strategy must be one of",
+		"aliases: heavy": "aliases must be one of",
+		"targets:\n  npm:\n    files:\n      - glob: a\n        pattern: b":      "npm is a built-in target",
+		"targets:\n  generate:\n    files:\n      - glob: a\n        pattern: b": "generate is a built-in target",
+		"targets:\n  docs: {}":                              "target docs has no files",
+		"targets:\n  docs:\n    files:\n      - glob: a":    "needs a glob and a pattern",
+		"generate:\n  - path: version.txt":                  "needs a language or a template",
+		"generate:\n  - template: a.tmpl":                   "needs a path for the template",
+		"generate:\n  - language: go\n    package: my-tool": "my-tool is not a valid identifier",
+		"preflight:\n  rules: [clean]":                      "unknown preflight rule clean",
+		"hooks:\n  after-lunch: [echo]":                     "unknown hook after-lunch",
+		"components:\n  api: {}":                            "component api has no path",
+		"write: [":                                          "invalid config",
+	}
+
+	for content, expected := range tests {
+		path := filepath.Join(t.TempDir(), ".tagger.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := loadConfig(path)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%q: expected an error containing %q, got %v", content, expected, err)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/MatthiasSchild/tagger/utils"
+	"github.com/MatthiasSchild/tagger/version"
+)
+
+// versionRange is an inclusive range of versions, a single version has equal bounds
+type versionRange struct {
+	from Tag
+	to   Tag
+}
+
+// parseVersionRange parses a version (v1.2.3) or an inclusive range (v1.2.0..v1.2.5).
+// The versions can have the tag prefix (e.g. api/v1.2.3 with the prefix api/v).
+// A version with an addition (v1.2.3-rc1) only matches the tag with this addition.
+func parseVersionRange(value string, prefix string) (versionRange, error) {
+	from, to, isRange := strings.Cut(value, "..")
+	if !isRange {
+		to = from
+	}
+	from = strings.TrimPrefix(from, prefix)
+	to = strings.TrimPrefix(to, prefix)
+
+	fromTag, err := version.Parse(from)
+	if err != nil {
+		return versionRange{}, err
+	}
+	toTag, err := version.Parse(to)
+	if err != nil {
+		return versionRange{}, err
+	}
+	if version.Compare(fromTag, toTag) > 0 {
+		return versionRange{}, fmt.Errorf("the range %s is empty, %s is higher than %s", value, from, to)
+	}
+	if isRange && (fromTag.String() != fromTag.StringSimple() || toTag.String() != toTag.StringSimple()) {
+		return versionRange{}, fmt.Errorf("the versions of a range must not have an addition")
+	}
+	return versionRange{from: fromTag, to: toTag}, nil
+}
+
+// isRange checks, if the range contains more than one version
+func (r versionRange) isRange() bool {
+	return version.Compare(r.from, r.to) != 0
+}
+
+// matches checks, if the tag name with the prefix is a version of the range
+func (r versionRange) matches(name string, prefix string) bool {
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	tag, err := version.Parse(name[len(prefix):])
+	if err != nil {
+		return false
+	}
+
+	if !r.isRange() && r.from.String() != r.from.StringSimple() {
+		return tag.Equals(r.from) && tag.PlusAddition == r.from.PlusAddition && tag.MinusAddition == r.from.MinusAddition
+	}
+	return version.Compare(tag, r.from) >= 0 && version.Compare(tag, r.to) <= 0
+}
+
+// String returns the range in the retract syntax of go.mod (v1.2.3 or [v1.2.0, v1.2.5])
+func (r versionRange) String() string {
+	if !r.isRange() {
+		addition := strings.TrimPrefix(r.from.String(), r.from.StringSimple())
+		return "v" + r.from.Version() + addition
+	}
+	return fmt.Sprintf("[v%s, v%s]", r.from.Version(), r.to.Version())
+}
+
+// filterTagNames returns the sorted tag names with the prefix of the versions in the range
+func filterTagNames(names []string, r versionRange, prefix string) []string {
+	result := make([]string, 0)
+	for _, name := range names {
+		if r.matches(name, prefix) {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// retractFiles returns the files, which can record a retraction: go.mod and CHANGELOG.md
+func retractFiles() []string {
+	result := make([]string, 0)
+	for _, path := range []string{"go.mod", "CHANGELOG.md"} {
+		if _, err := os.Stat(path); err == nil {
+			result = append(result, path)
+		}
+	}
+	return result
+}
+
+// recordRetraction records the retraction of the versions in go.mod (as retract directive)
+// and in CHANGELOG.md (as item of the sections of the deleted versions, named without the tag prefix)
+func recordRetraction(r versionRange, names []string, reason string, prefix string) error {
+	note := reason
+	if note == "" {
+		note = "do not use this version"
+	}
+
+	for _, path := range retractFiles() {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", path, err)
+		}
+
+		result := string(content)
+		if path == "go.mod" {
+			result = utils.AddGoModRetract(result, r.String(), reason)
+		} else {
+			recorded := false
+			for _, name := range names {
+				var found bool
+				result, found = utils.AddChangelogRetraction(result, strings.TrimPrefix(name, prefix), note)
+				recorded = recorded || found
+			}
+			if !recorded {
+				fmt.Fprintf(os.Stderr, "Warning: %s has no section of the deleted versions\n", path)
+				continue
+			}
+		}
+
+		err = os.WriteFile(path, []byte(result), 0644)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %v", path, err)
+		}
+	}
+	return nil
+}
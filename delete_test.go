@@ -0,0 +1,65 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseVersionRange(t *testing.T) {
+	tests := []struct {
+		value    string
+		prefix   string
+		expected string
+	}{
+		{"v1.2.3", "v", "v1.2.3"},
+		{"1.2.3-rc1", "v", "v1.2.3-rc1"},
+		{"v1.2.0..v1.2.5", "v", "[v1.2.0, v1.2.5]"},
+		{"api/v1.2.0..api/v1.3.0", "api/v", "[v1.2.0, v1.3.0]"},
+		{"v1.2.0..v1.2.0", "v", "v1.2.0"},
+	}
+	for _, test := range tests {
+		result, err := parseVersionRange(test.value, test.prefix)
+		if err != nil {
+			t.Errorf("%s: %v", test.value, err)
+			continue
+		}
+		if result.String() != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.value, test.expected, result)
+		}
+	}
+
+	for _, invalid := range []string{"latest", "v1.2.5..v1.2.0", "v1.2.0-rc1..v1.2.5", "v1.2.0..", "..v1.2.0"} {
+		if _, err := parseVersionRange(invalid, "v"); err == nil {
+			t.Errorf("%s should be invalid", invalid)
+		}
+	}
+}
+
+func TestFilterTagNames(t *testing.T) {
+	names := []string{
+		"v1.1.9", "v1.2.0", "v1.2.3", "v1.2.3-rc1", "v1.2.3+7", "v1.2.5", "v1.3.0", "v1", "release",
+		"api/v1.2.0", "api/v1.2.3-abcdef", "api/v1.4.0",
+	}
+
+	tests := []struct {
+		value    string
+		prefix   string
+		expected []string
+	}{
+		{"v1.2.3", "v", []string{"v1.2.3", "v1.2.3+7", "v1.2.3-rc1"}},
+		{"v1.2.3-rc1", "v", []string{"v1.2.3-rc1"}},
+		{"v1.2.0..v1.2.5", "v", []string{"v1.2.0", "v1.2.3", "v1.2.3+7", "v1.2.3-rc1", "v1.2.5"}},
+		{"v1.2.0..v1.3.0", "api/v", []string{"api/v1.2.0", "api/v1.2.3-abcdef"}},
+		{"api/v1.4.0", "api/v", []string{"api/v1.4.0"}},
+		{"v2.0.0", "v", []string{}},
+	}
+	for _, test := range tests {
+		versions, err := parseVersionRange(test.value, test.prefix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result := filterTagNames(names, versions, test.prefix); !slices.Equal(result, test.expected) {
+			t.Errorf("%s with prefix %s: expected %v, got %v", test.value, test.prefix, test.expected, result)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MatthiasSchild/tagger/version"
+)
+
+// headVersion returns the highest version tag pointing at HEAD, including its addition
+func headVersion() (Tag, error) {
+	names, err := repo.TagsAt("HEAD")
+	if err != nil {
+		return Tag{}, fmt.Errorf("failed to fetch git tags of HEAD: %v", err)
+	}
+
+	var result *Tag
+	for _, name := range names {
+		value, ok := strings.CutPrefix(name, tagPrefix)
+		if !ok {
+			continue
+		}
+		// The "v" is optional for version.Parse, so it would accept v1.2.3 after an "api/" prefix
+		if strings.HasPrefix(value, "v") {
+			continue
+		}
+		tag, err := version.Parse(value)
+		if err != nil {
+			continue
+		}
+		tag.Prefix = tagPrefix
+		// Releases win against prereleases of the same version
+		if result == nil || version.Compare(tag, *result) > 0 ||
+			(version.Compare(tag, *result) == 0 && result.MinusAddition != "" && tag.MinusAddition == "") {
+			result = &tag
+		}
+	}
+	if result == nil {
+		return Tag{}, fmt.Errorf("HEAD has no version tag, pass the version as argument")
+	}
+	return *result, nil
+}
+
+// dockerTags returns the image tags of the version: the version itself (e.g. 1.2.3),
+// its minor and major line (1.2 and 1) and latest, but only when the version is the highest of the line.
+// Prereleases (see isPrerelease) only get their own tag. Docker tags cannot contain "+",
+// so it is replaced with "_". The commit is the one of the version, with sha the tag sha-<short commit> is added.
+func dockerTags(tag Tag, index *version.Index, commit string, sha bool) []string {
+	result := []string{strings.ReplaceAll(fullVersion(tag), "+", "_")}
+
+	if !isPrerelease(tag, commit) {
+		if index.HighestInMinor(tag) {
+			result = append(result, fmt.Sprintf("%d.%d", tag.Major, tag.Minor))
+		}
+		if index.HighestInMajor(tag) {
+			result = append(result, fmt.Sprintf("%d", tag.Major))
+		}
+		if latest, ok := index.Latest(); !ok || version.Compare(tag, latest) >= 0 {
+			result = append(result, "latest")
+		}
+	}
+
+	if sha {
+		result = append(result, "sha-"+commit[:min(7, len(commit))])
+	}
+	return result
+}
+
+// isPrerelease checks, if the minus addition of the tag marks a prerelease (e.g. v1.3.0-rc1).
+// The addition of --hash (e.g. v1.2.3-1fa342) is the beginning of the commit hash and marks a release.
+func isPrerelease(tag Tag, commit string) bool {
+	return tag.MinusAddition != "" && !strings.HasPrefix(commit, tag.MinusAddition)
+}
+
+// ociLabels returns the OCI image labels of the version and the commit
+func ociLabels(tag Tag, commit string) []string {
+	return []string{
+		"org.opencontainers.image.version=" + fullVersion(tag),
+		"org.opencontainers.image.revision=" + commit,
+	}
+}
+
+// fullVersion returns the version with its addition, but without prefix (e.g. 1.2.3-rc1)
+func fullVersion(tag Tag) string {
+	return tag.Version() + strings.TrimPrefix(tag.String(), tag.StringSimple())
+}
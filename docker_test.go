@@ -0,0 +1,66 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/MatthiasSchild/tagger/repository"
+	"github.com/MatthiasSchild/tagger/version"
+)
+
+func TestDockerTags(t *testing.T) {
+	const commit = "1fa342b9c0d1e2f3a4b5c6d7e8f901234567890a"
+	index := version.NewIndex([]string{"v1.2.3", "v1.2.4-1fa342", "v1.3.0"}, "v")
+
+	tests := []struct {
+		value    string
+		sha      bool
+		expected []string
+	}{
+		{"v1.3.0", false, []string{"1.3.0", "1.3", "1", "latest"}},
+		{"v1.2.4", false, []string{"1.2.4", "1.2"}},
+		{"v1.3.1+7", true, []string{"1.3.1_7", "1.3", "1", "latest", "sha-1fa342b"}},
+		{"v2.0.0-rc1", false, []string{"2.0.0-rc1"}},
+		{"v1.3.1-1fa342", false, []string{"1.3.1-1fa342", "1.3", "1", "latest"}},
+		{"v3.0.0", false, []string{"3.0.0", "3.0", "3", "latest"}},
+	}
+	for _, test := range tests {
+		tag, err := version.Parse(test.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result := dockerTags(tag, index, commit, test.sha); !slices.Equal(result, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.value, test.expected, result)
+		}
+	}
+}
+
+func TestVersionCommit(t *testing.T) {
+	memory := repository.NewMemory()
+	released := memory.Commit("feat: release")
+	if err := memory.CreateTag("v1.2.3-"+released[:6], released, ""); err != nil {
+		t.Fatal(err)
+	}
+	head := memory.Commit("fix: later")
+	repo = memory
+	tagPrefix = version.DefaultPrefix
+
+	tests := map[string]string{
+		"v1.2.3-" + released[:6]: released,
+		"v1.2.3":                 released,
+		"v1.2.4":                 head,
+	}
+	for value, expected := range tests {
+		tag, err := version.Parse(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		commit, err := versionCommit(tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if commit != expected {
+			t.Errorf("%s: expected commit %s, got %s", value, expected, commit)
+		}
+	}
+}
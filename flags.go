@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+
+	"github.com/MatthiasSchild/tagger/release"
 )
 
 var (
@@ -13,12 +15,61 @@ var (
 	flagDry      bool
 	flagWrite    string
 	flagBuild    bool
+	flagInitial  string
+	flagCommit   string
+	flagAliases  string
+
+	flagChartBump   string
+	flagAppVersion  bool
+	flagBuildNumber int
+
+	flagConfig    string
+	flagGit       string
+	flagRemote    string
+	flagComponent string
+	flagCascade   bool
+
+	flagForceBranch    bool
+	flagForceDetached  bool
+	flagForceBehind    bool
+	flagForceTagged    bool
+	flagForceUnchanged bool
+
+	flagInitYes   bool
+	flagInitForce bool
+
+	flagSyncTargets       []string
+	flagSyncCommitChanges bool
+
+	flagUndoForce bool
+	flagUndoYes   bool
+
+	flagDeleteYes     bool
+	flagDeleteRetract bool
+	flagDeleteReason  string
+
+	flagBackfillFrom string
+
+	flagStatusJson bool
+
+	flagDockerSha    bool
+	flagDockerImage  string
+	flagDockerLabels bool
 )
 
 func validateFlags() error {
-	// When neither major, minor, patch, datetime nor hash is set, set patch
+	// When neither major, minor, patch, datetime nor hash is set, use the strategy of the config (default: patch)
 	if !flagMajor && !flagMinor && !flagPatch && !flagDateTime && flagHash == 0 {
-		flagPatch = true
+		switch config.Strategy {
+		case "major":
+			flagMajor = true
+		case "minor":
+			flagMinor = true
+		case "datetime":
+			flagDateTime = true
+		default:
+			flagPatch = true
+		}
 	}
 
 	// Only one of those are allowed to be set: major, minor, patch
@@ -39,5 +90,49 @@ func validateFlags() error {
 		fmt.Println("Just one character? This is useless, but here you go...")
 	}
 
+	// Cascading bumps start at a component and write the targets of every component
+	if flagCascade && flagComponent == "" {
+		return fmt.Errorf("--cascade needs a --component")
+	}
+	if flagCascade && flagWrite != "" {
+		return fmt.Errorf("when using --cascade, the targets of the components are written instead of --write")
+	}
+
+	// Another commit than HEAD can only be tagged, its version files are not written
+	if flagCommit != "" && (flagWrite != "" || flagCascade) {
+		return fmt.Errorf("when using --commit, --write and --cascade are not allowed")
+	}
+
+	// The chart version is either set to the new tag or bumped on its own
+	switch flagChartBump {
+	case "tag", "major", "minor", "patch":
+	default:
+		return fmt.Errorf("chart-bump must be one of: tag, major, minor, patch")
+	}
+
 	return nil
 }
+
+// releaseOptionsFromFlags builds the options of the release pipeline from the validated flags
+func releaseOptionsFromFlags() release.Options {
+	options := release.Options{
+		DateTime: flagDateTime,
+		Hash:     flagHash,
+		Write:    flagWrite,
+		Targets:  targetOptions(),
+		Hook:     runHooks,
+		Prefix:   tagPrefix,
+		Commit:   flagCommit,
+		Aliases:  aliasKind(),
+		Dry:      flagDry,
+	}
+	switch {
+	case flagMajor:
+		options.Bump = "major"
+	case flagMinor:
+		options.Bump = "minor"
+	case flagPatch:
+		options.Bump = "patch"
+	}
+	return options
+}
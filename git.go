@@ -2,110 +2,181 @@ package main
 
 import (
 	"fmt"
-	"os/exec"
-	"regexp"
-	"slices"
+	"io/fs"
+	"os"
 	"strings"
+
+	"github.com/MatthiasSchild/tagger/release"
+	"github.com/MatthiasSchild/tagger/repository"
+	"github.com/MatthiasSchild/tagger/version"
 )
 
+// repo is the git repository of the working directory, opened with the configured backend
+var repo repository.Repository
+
+// localRepo is the repository without the tags of --remote, for deleting local tags
+var localRepo repository.Repository
+
+// tagPrefix is the prefix of the version tags: the one of the component or the config file, "v" by default
+var tagPrefix = version.DefaultPrefix
+
+// versionFiles is the file system the version files are read from:
+// the working directory or the files of the revision to tag (--commit)
+var versionFiles fs.FS = os.DirFS(".")
+
 func getAllGitTags() ([]Tag, error) {
-	result := make([]Tag, 0)
+	return getGitTagsWithPrefix(tagPrefix)
+}
 
-	cmd := exec.Command("git", "tag", "-l")
-	out, err := cmd.Output()
+// getGitTagsWithPrefix returns the version tags with the given prefix (e.g. "api/v" for api/v1.2.3)
+func getGitTagsWithPrefix(prefix string) ([]Tag, error) {
+	names, err := repo.Tags()
 	if err != nil {
 		return nil, err
 	}
 
-	rawTags := strings.Split(string(out), "\n")
-	cleanTags := make([]string, 0)
+	return version.ParseTags(names, prefix), nil
+}
 
-	for _, tag := range rawTags {
-		pattern := "^v([0-9]+)\\.([0-9]+)\\.([0-9]+)([+-][a-zA-Z0-9]+)?$"
-		matched, err := regexp.MatchString(pattern, tag)
-		if err != nil {
-			continue
-		}
-		if matched {
-			cleanTag := strings.SplitN(strings.SplitN(tag, "+", 2)[0], "-", 2)[0]
-			if !slices.Contains(cleanTags, cleanTag) {
-				cleanTags = append(cleanTags, cleanTag)
-			}
-		}
+// getLatestGitTag returns the highest version tag with the given prefix (e.g. "api/v" for api/v1.2.3)
+// and the name of its tag, which contains the addition of the tag (e.g. v1.2.3-abcdef).
+// The tag is nil, when no version tags exist.
+func getLatestGitTag(prefix string) (*Tag, string, error) {
+	names, err := repo.Tags()
+	if err != nil {
+		return nil, "", err
 	}
 
-	for _, tag := range cleanTags {
-		var major, minor, patch int
+	index := version.NewIndex(names, prefix)
+	latest, ok := index.Latest()
+	if !ok {
+		return nil, "", nil
+	}
+	return &latest, index.Name(latest), nil
+}
+
+// getGitTagName returns the name of the tag of the version, see version.Index.Name
+func getGitTagName(tag Tag) (string, error) {
+	names, err := repo.Tags()
+	if err != nil {
+		return "", err
+	}
 
-		_, err = fmt.Sscanf(tag, "v%d.%d.%d", &major, &minor, &patch)
-		if err != nil {
-			continue
-		}
+	prefix := tag.Prefix
+	if prefix == "" {
+		prefix = tagPrefix
+	}
+	name := version.NewIndex(names, prefix).Name(tag)
+	if name == "" {
+		return "", fmt.Errorf("version %s is not tagged", tag)
+	}
+	return name, nil
+}
 
-		result = append(result, Tag{major, minor, patch, "", ""})
+// versionCommit returns the commit of the tag of the version, HEAD when the version is not tagged yet
+func versionCommit(tag Tag) (string, error) {
+	if commit, err := repo.Resolve(tag.String()); err == nil {
+		return commit, nil
 	}
+	// The version might be passed without the addition of its tag (e.g. v1.2.3 for v1.2.3-1fa342)
+	if name, err := getGitTagName(tag); err == nil {
+		return repo.Resolve(name)
+	}
+	return getCurrentGitHash()
+}
 
-	return result, nil
+// getHeadGitTags returns the version tags pointing at the current commit
+func getHeadGitTags() ([]Tag, error) {
+	return getGitTagsAt("HEAD")
 }
 
-func getCurrentGitHash() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "HEAD")
-	out, err := cmd.Output()
+// getGitTagsAt returns the version tags pointing at the commit of the revision
+func getGitTagsAt(revision string) ([]Tag, error) {
+	names, err := repo.TagsAt(revision)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return strings.Trim(string(out), "\r\n\t "), nil
+	return version.ParseTags(names, tagPrefix), nil
 }
 
-func getLatestTag(tags []Tag) Tag {
-	var latest Tag
-	for _, tag := range tags {
-		if tag.Major > latest.Major {
-			latest = tag
-		} else if tag.Major == latest.Major {
-			if tag.Minor > latest.Minor {
-				latest = tag
-			} else if tag.Minor == latest.Minor {
-				if tag.Patch > latest.Patch {
-					latest = tag
-				}
-			}
-		}
+// tagRevision returns the revision to tag: the one of --commit or HEAD
+func tagRevision() string {
+	if flagCommit != "" {
+		return flagCommit
 	}
-	return latest
+	return "HEAD"
 }
 
+func getCurrentGitHash() (string, error) {
+	return repo.Resolve("HEAD")
+}
+
+// createTag creates the tag at the revision to tag (see tagRevision) and moves its aliases, when enabled
 func createTag(tag Tag) error {
-	cmd := exec.Command("git", "tag", "-a", tag.String(), "-m", tag.String())
-	err := cmd.Run()
+	err := repo.CreateTag(tag.String(), tagRevision(), tag.String())
 	if err != nil {
 		return err
 	}
-	return nil
+	return moveAliases(tag)
 }
 
-func hasUncommittedChanges() (bool, error) {
-	cmd := exec.Command("git", "diff", "HEAD")
-	out, err := cmd.Output()
-	if err != nil {
-		return false, err
+// aliasesNone disables the floating alias tags of the config file with --aliases=none
+const aliasesNone = "none"
+
+// aliasKind returns the kind of the floating alias tags (see release.Aliases), empty when they are not moved.
+// The --aliases flag wins over the config file.
+func aliasKind() string {
+	switch flagAliases {
+	case "":
+		return config.Aliases
+	case aliasesNone:
+		return ""
 	}
-
-	trimmedOutput := strings.Trim(string(out), "\r\n\t ")
-	return len(trimmedOutput) > 0, nil
+	return flagAliases
 }
 
-func commitAll(message string) error {
-	cmd1 := exec.Command("git", "add", "--all")
-	err := cmd1.Run()
+// moveAliases moves the floating alias tags (e.g. v1 and v1.2) to the tag, when it is the highest of their line
+func moveAliases(tag Tag) error {
+	kind := aliasKind()
+	if kind == "" {
+		return nil
+	}
+
+	prefix := tag.Prefix
+	if prefix == "" {
+		prefix = tagPrefix
+	}
+	names, err := repo.Tags()
 	if err != nil {
 		return err
 	}
-	cmd2 := exec.Command("git", "commit", "-m", message)
-	err = cmd2.Run()
+	aliases := release.Aliases(version.NewIndex(names, prefix), tag)
+	err = release.MoveAliases(repo, aliases, tagRevision(), kind)
 	if err != nil {
 		return err
 	}
+	if len(aliases) > 0 {
+		fmt.Printf("Moved aliases %s to %s\n", strings.Join(aliases, ", "), tag)
+	}
 	return nil
 }
+
+func hasUncommittedChanges() (bool, error) {
+	return repo.HasUncommittedChanges()
+}
+
+func commitAll(message string) error {
+	return repo.CommitAll(message)
+}
+
+// countCommitsSince counts the commits since the given tag up to the revision to tag, which touch the path.
+// When the tag is empty, all commits touching the path are counted.
+func countCommitsSince(tag string, path string) (int, error) {
+	return repo.CountCommits(tag, tagRevision(), path)
+}
+
+// deleteTag deletes a local tag
+func deleteTag(name string) error {
+	return repo.DeleteTag(name)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/MatthiasSchild/tagger/release"
+)
+
+func TestAliasKind(t *testing.T) {
+	defer func() { flagAliases = "" }()
+
+	tests := []struct {
+		args     []string
+		config   string
+		expected string
+	}{
+		{nil, "", ""},
+		{nil, release.AliasesAnnotated, release.AliasesAnnotated},
+		{[]string{"--aliases"}, "", release.AliasesLightweight},
+		{[]string{"--aliases"}, release.AliasesAnnotated, release.AliasesLightweight},
+		{[]string{"--aliases=annotated"}, release.AliasesLightweight, release.AliasesAnnotated},
+		{[]string{"--aliases=none"}, release.AliasesAnnotated, ""},
+	}
+	for _, test := range tests {
+		flagAliases = ""
+		if err := TagCmd.Flags().Parse(test.args); err != nil {
+			t.Fatal(err)
+		}
+		config = Config{Aliases: test.config}
+
+		if kind := aliasKind(); kind != test.expected {
+			t.Errorf("%v with config %q: expected %q, got %q", test.args, test.config, test.expected, kind)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+
+	"github.com/MatthiasSchild/tagger/release"
+)
+
+// hookPoints contains the hook points of the config file in the order they run
+var hookPoints = []string{release.HookPreBump, release.HookPostWrite, release.HookPreTag, release.HookPostTag}
+
+// runHooks runs the commands of the hook point from the config file with the shell.
+// The version data is passed as TAGGER_* environment variables.
+func runHooks(point string, result release.Result) error {
+	for _, command := range config.Hooks[point] {
+		fmt.Printf("Running %s hook: %s\n", point, command)
+
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("cmd", "/C", command)
+		} else {
+			cmd = exec.Command("sh", "-c", command)
+		}
+		cmd.Env = append(os.Environ(), hookEnvironment(point, result)...)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		err := cmd.Run()
+		if err != nil {
+			return fmt.Errorf("%s: %v", command, err)
+		}
+	}
+	return nil
+}
+
+// hookEnvironment returns the environment variables passed to the hooks
+func hookEnvironment(point string, result release.Result) []string {
+	previous, previousVersion := "", ""
+	if !result.Initial {
+		previous, previousVersion = result.Previous.String(), result.Previous.Version()
+	}
+
+	return []string{
+		"TAGGER_HOOK=" + point,
+		"TAGGER_TAG=" + result.Next.String(),
+		"TAGGER_VERSION=" + result.Next.Version(),
+		"TAGGER_MAJOR=" + strconv.Itoa(result.Next.Major),
+		"TAGGER_MINOR=" + strconv.Itoa(result.Next.Minor),
+		"TAGGER_PATCH=" + strconv.Itoa(result.Next.Patch),
+		"TAGGER_PREFIX=" + result.Next.Prefix,
+		"TAGGER_PREVIOUS=" + previous,
+		"TAGGER_PREVIOUS_VERSION=" + previousVersion,
+		"TAGGER_COMPONENT=" + flagComponent,
+		"TAGGER_ROOT=" + rootDir,
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/MatthiasSchild/tagger/targets"
+)
+
+// initConfig creates the config writing the versions into the detected manifests.
+// For every manifest, confirm is asked, if it should be written (by default, when its version can be read).
+func initConfig(manifests []targets.Manifest, confirm func(label string, defaultYes bool) (bool, error)) (Config, error) {
+	result := Config{}
+	for _, manifest := range manifests {
+		readable := manifest.Version != "" || manifest.Generate != nil
+		use, err := confirm(fmt.Sprintf("Write the version into %s", manifest.Path), readable)
+		if err != nil {
+			return Config{}, err
+		}
+		if !use {
+			continue
+		}
+
+		result.Write = append(result.Write, manifest.Target)
+		if manifest.Generate != nil {
+			result.Generate = append(result.Generate, *manifest.Generate)
+		}
+		if target, ok := targets.SuggestedTargets[manifest.Target]; ok {
+			if result.Targets == nil {
+				result.Targets = make(map[string]TargetConfig)
+			}
+			result.Targets[manifest.Target] = target
+		}
+	}
+	return result, nil
+}
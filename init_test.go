@@ -0,0 +1,62 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/MatthiasSchild/tagger/targets"
+)
+
+func TestInitConfig(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json":   {Data: []byte(`{"version": "1.0.0"}`)},
+		"Chart.yaml":     {Data: []byte("name: app\nversion: latest\n")},
+		"go.mod":         {Data: []byte("module example.com/my-lib\n")},
+		"lib.go":         {Data: []byte("// Package mylib does things\npackage mylib\n")},
+		"VERSION":        {Data: []byte("1.0.0\n")},
+		"cmd/tool/x.go":  {Data: []byte("package main\n")},
+		"lib_test.go":    {Data: []byte("package mylib_test\n")},
+		"pyproject.toml": {Data: []byte("[project]\nname = \"app\"\n")},
+	}
+	acceptDefault := func(label string, defaultYes bool) (bool, error) {
+		return defaultYes, nil
+	}
+
+	result, err := initConfig(targets.DetectManifests(fsys), acceptDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(result.Write, []string{"npm", "generate", "version-file"}) {
+		t.Errorf("expected the files with a version to be written, got %v", result.Write)
+	}
+	if len(result.Generate) != 1 || result.Generate[0] != (GenerateFile{Language: "go", Path: "version.go", Package: "mylib"}) {
+		t.Errorf("expected version.go in the package of the library, got %+v", result.Generate)
+	}
+	if _, ok := result.Targets["version-file"]; !ok || len(result.Targets) != 1 {
+		t.Errorf("expected the suggested target of the VERSION file only, got %v", result.Targets)
+	}
+}
+
+func TestInitConfigGoModule(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+		want  string
+	}{
+		{"command", fstest.MapFS{"go.mod": {Data: []byte("module example.com/tool\n")}, "main.go": {Data: []byte("package main\n")}}, "main"},
+		{"no root package", fstest.MapFS{"go.mod": {Data: []byte("module example.com/my-tool\n")}, "cmd/x/main.go": {Data: []byte("package main\n")}}, "mytool"},
+		{"major version", fstest.MapFS{"go.mod": {Data: []byte("module github.com/org/go-lib/v2\n")}}, "golib"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := initConfig(targets.DetectManifests(test.files), func(string, bool) (bool, error) { return true, nil })
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Generate) != 1 || result.Generate[0].Package != test.want {
+				t.Errorf("expected package %s, got %+v", test.want, result.Generate)
+			}
+		})
+	}
+}
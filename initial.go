@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/MatthiasSchild/tagger/targets"
+	"github.com/MatthiasSchild/tagger/version"
+)
+
+// defaultInitialVersion is the first version of repositories without tags
+const defaultInitialVersion = "v0.1.0"
+
+// initialVersion returns the first version of a repository without tags and where it comes from.
+// It is set with --initial or in the config file, either as version (e.g. v1.0.0)
+// or as a target, whose version file is read (e.g. npm for the package.json).
+func initialVersion() (Tag, string, error) {
+	return initialVersionOf(tagPrefix, versionFiles)
+}
+
+// initialVersionOf returns the first version with the prefix, see initialVersion.
+// The version file of a target is read from the file system (e.g. the directory of a component).
+func initialVersionOf(prefix string, files fs.FS) (Tag, string, error) {
+	value, source := defaultInitialVersion, "default"
+	if flagInitial != "" {
+		value, source = flagInitial, "--initial"
+	} else if config.Initial != "" {
+		value, source = config.Initial, "config"
+	}
+
+	if tag, err := version.Parse(value); err == nil {
+		tag = tag.Clone()
+		tag.Prefix = prefix
+		return tag, source, nil
+	}
+
+	reader, ok := targets.Readers[value]
+	if !ok {
+		return Tag{}, "", fmt.Errorf("initial version %s must be a version (e.g. v0.1.0) or a target with a version file", value)
+	}
+	tag, err := reader(files)
+	if err != nil {
+		return Tag{}, "", fmt.Errorf("failed to read the initial version of %s: %v", value, err)
+	}
+	tag = tag.Clone()
+	tag.Prefix = prefix
+	return tag, targets.ManifestPaths[value], nil
+}
+
+// initialVersionIfUntagged returns the initial version, when no version tags exist yet, otherwise nil
+func initialVersionIfUntagged() (*Tag, string, error) {
+	tags, err := getAllGitTags()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch git tags: %v", err)
+	}
+	if len(tags) > 0 {
+		return nil, "", nil
+	}
+
+	tag, source, err := initialVersion()
+	if err != nil {
+		return nil, "", err
+	}
+	return &tag, source, nil
+}
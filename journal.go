@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MatthiasSchild/tagger/journal"
+)
+
+// journalEntry contains the commits and tags created by this run, see recordJournal
+var journalEntry = &journal.Entry{}
+
+// recordJournal wraps the repository, so the created commits and tags are recorded for "tagger undo"
+func recordJournal() {
+	if repo.GitDir() == "" {
+		return
+	}
+	repo = journal.Record(repo, journalEntry)
+}
+
+// saveJournal appends the entry of this run to the journal, when anything was created.
+// It runs after the command, even when it failed, so partial releases can be undone as well.
+func saveJournal() error {
+	if repo == nil || journalEntry.Empty() {
+		return nil
+	}
+
+	j, err := journal.Load(journal.Path(repo.GitDir()))
+	if err != nil {
+		return err
+	}
+
+	journalEntry.Time = time.Now()
+	journalEntry.Command = "tagger " + strings.Join(os.Args[1:], " ")
+	journalEntry.Branch, _ = repo.Branch()
+	j.Add(*journalEntry)
+	return j.Save()
+}
+
+// loadJournal loads the journal of the repository
+func loadJournal() (*journal.Journal, error) {
+	gitDir := repo.GitDir()
+	if gitDir == "" {
+		return nil, fmt.Errorf("the repository has no git directory for the journal")
+	}
+	return journal.Load(journal.Path(gitDir))
+}
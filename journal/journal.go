@@ -0,0 +1,104 @@
+// Package journal records the commits and tags created by tagger, so the last release can be undone.
+// The journal is a JSON file in the .git directory, it is never committed or pushed.
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// MaxEntries is the number of entries kept in the journal, older entries are dropped
+const MaxEntries = 100
+
+// Tag is a tag created by tagger
+type Tag struct {
+	Name   string `json:"name"`
+	Commit string `json:"commit"`
+}
+
+// Entry contains everything a single run of tagger created
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"`
+	Branch  string    `json:"branch,omitempty"`
+	// Head is the commit HEAD pointed to before the first commit was created
+	Head string `json:"head,omitempty"`
+	// Commits are the created commits, from the oldest to the newest
+	Commits []string `json:"commits,omitempty"`
+	Tags    []Tag    `json:"tags,omitempty"`
+	Undone  bool     `json:"undone,omitempty"`
+}
+
+// Empty checks, if nothing was recorded
+func (e *Entry) Empty() bool {
+	return len(e.Commits) == 0 && len(e.Tags) == 0
+}
+
+// Journal is the list of recorded entries, from the oldest to the newest
+type Journal struct {
+	path    string
+	Entries []Entry
+}
+
+// Path returns the path of the journal in the git directory
+func Path(gitDir string) string {
+	return filepath.Join(gitDir, "tagger", "journal.json")
+}
+
+// Load reads the journal, a missing file results in an empty journal
+func Load(path string) (*Journal, error) {
+	journal := &Journal{path: path, Entries: make([]Entry, 0)}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return journal, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(content, &journal.Entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return journal, nil
+}
+
+// Save writes the journal, keeping the newest MaxEntries entries
+func (j *Journal) Save() error {
+	if len(j.Entries) > MaxEntries {
+		j.Entries = j.Entries[len(j.Entries)-MaxEntries:]
+	}
+
+	content, err := json.MarshalIndent(j.Entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(j.path), 0755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(j.path, append(content, '\n'), 0644)
+}
+
+// Add appends the entry, empty entries are ignored
+func (j *Journal) Add(entry Entry) {
+	if entry.Empty() {
+		return
+	}
+	j.Entries = append(j.Entries, entry)
+}
+
+// Last returns the newest entry, which is not undone yet
+func (j *Journal) Last() (*Entry, bool) {
+	for i := len(j.Entries) - 1; i >= 0; i-- {
+		if !j.Entries[i].Undone {
+			return &j.Entries[i], true
+		}
+	}
+	return nil, false
+}
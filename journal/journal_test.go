@@ -0,0 +1,129 @@
+package journal_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/MatthiasSchild/tagger/journal"
+	"github.com/MatthiasSchild/tagger/release"
+	"github.com/MatthiasSchild/tagger/repository"
+)
+
+func TestUndo(t *testing.T) {
+	memory := repository.NewMemory()
+	_ = memory.CreateTag("v1.0.0", "HEAD", "v1.0.0")
+	memory.Commit("feature")
+	before, _ := memory.Resolve("HEAD")
+
+	entry := &journal.Entry{Branch: "main"}
+	repo := journal.Record(memory, entry)
+	memory.Change("package.json")
+	err := repo.CommitAll("v1.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = release.Run(repo, release.Options{Bump: "patch"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Head != before || len(entry.Commits) != 1 || len(entry.Tags) != 1 || entry.Tags[0].Name != "v1.0.1" {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+
+	undo, err := journal.PlanUndo(repo, entry, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(undo.Blockers) != 0 || undo.Reset != before {
+		t.Errorf("unexpected undo %+v", undo)
+	}
+	err = undo.Execute(repo, entry, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, _ := memory.Resolve("HEAD")
+	tags, _ := memory.Tags()
+	if head != before || len(tags) != 1 || !entry.Undone {
+		t.Errorf("release was not undone: HEAD %s, tags %v", head, tags)
+	}
+}
+
+func TestUndoBlockers(t *testing.T) {
+	memory := repository.NewMemory()
+	entry := &journal.Entry{Branch: "main"}
+	repo := journal.Record(memory, entry)
+	memory.Change("package.json")
+	_ = repo.CommitAll("v1.0.0")
+	_ = repo.CreateTag("v1.0.0", "HEAD", "v1.0.0")
+	memory.SetRemoteTag("origin", "v1.0.0", entry.Commits[0])
+	_ = memory.SetPushed("HEAD")
+	memory.Commit("on top")
+
+	undo, err := journal.PlanUndo(repo, entry, "origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(undo.Blockers) != 3 || len(undo.RemoteTags) != 1 {
+		t.Errorf("expected remote tag, moved HEAD and pushed commit as blockers, got %v", undo.Blockers)
+	}
+
+	memory.Change("README.md")
+	_, err = journal.PlanUndo(repo, entry, "")
+	if err == nil {
+		t.Error("uncommitted changes should never be discarded")
+	}
+}
+
+func TestUndoOtherBranch(t *testing.T) {
+	memory := repository.NewMemory()
+	entry := &journal.Entry{Branch: "main"}
+	repo := journal.Record(memory, entry)
+	memory.Change("package.json")
+	_ = repo.CommitAll("v1.0.0")
+	_ = repo.CreateTag("v1.0.0", "HEAD", "v1.0.0")
+
+	// Even forced, the release commit must not be reset on another branch
+	memory.SetBranch("feature")
+	_, err := journal.PlanUndo(repo, entry, "")
+	if err == nil {
+		t.Error("expected the undo on another branch to be refused")
+	}
+
+	memory.SetBranch("")
+	_, err = journal.PlanUndo(repo, entry, "")
+	if err == nil {
+		t.Error("expected the undo on a detached HEAD to be refused")
+	}
+}
+
+func TestJournal(t *testing.T) {
+	path := journal.Path(t.TempDir())
+	j, err := journal.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := j.Last(); ok {
+		t.Error("a new journal should be empty")
+	}
+
+	j.Add(journal.Entry{})
+	for i := 0; i < journal.MaxEntries+1; i++ {
+		j.Add(journal.Entry{Tags: []journal.Tag{{Name: "v1.0.0"}}, Undone: i == journal.MaxEntries})
+	}
+	err = j.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j, err = journal.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(j.Entries) != journal.MaxEntries || filepath.Base(path) != "journal.json" {
+		t.Errorf("expected %d entries, got %d", journal.MaxEntries, len(j.Entries))
+	}
+	last, ok := j.Last()
+	if !ok || last != &j.Entries[journal.MaxEntries-2] {
+		t.Error("the undone entry should be skipped")
+	}
+}
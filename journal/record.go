@@ -0,0 +1,67 @@
+package journal
+
+import (
+	"slices"
+
+	"github.com/MatthiasSchild/tagger/repository"
+)
+
+// recordingRepository records the created commits and tags into an entry
+type recordingRepository struct {
+	repository.Repository
+	entry *Entry
+}
+
+// Record returns a repository, which records the commits and tags it creates into the entry.
+// Deleted tags are removed from the entry, so reverted operations are not undone again.
+func Record(r repository.Repository, entry *Entry) repository.Repository {
+	return &recordingRepository{Repository: r, entry: entry}
+}
+
+func (r *recordingRepository) CommitAll(message string) error {
+	head, err := r.Resolve("HEAD")
+	if err != nil {
+		return err
+	}
+
+	err = r.Repository.CommitAll(message)
+	if err != nil {
+		return err
+	}
+
+	commit, err := r.Resolve("HEAD")
+	if err != nil {
+		return err
+	}
+	if len(r.entry.Commits) == 0 {
+		r.entry.Head = head
+	}
+	r.entry.Commits = append(r.entry.Commits, commit)
+	return nil
+}
+
+func (r *recordingRepository) CreateTag(name string, revision string, message string) error {
+	err := r.Repository.CreateTag(name, revision, message)
+	if err != nil {
+		return err
+	}
+
+	commit, err := r.Resolve(revision)
+	if err != nil {
+		return err
+	}
+	r.entry.Tags = append(r.entry.Tags, Tag{Name: name, Commit: commit})
+	return nil
+}
+
+func (r *recordingRepository) DeleteTag(name string) error {
+	err := r.Repository.DeleteTag(name)
+	if err != nil {
+		return err
+	}
+
+	r.entry.Tags = slices.DeleteFunc(r.entry.Tags, func(tag Tag) bool {
+		return tag.Name == name
+	})
+	return nil
+}
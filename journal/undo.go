@@ -0,0 +1,141 @@
+package journal
+
+import (
+	"fmt"
+
+	"github.com/MatthiasSchild/tagger/repository"
+)
+
+// Undo describes how an entry is undone
+type Undo struct {
+	// Tags are the local tags to delete
+	Tags []string
+	// RemoteTags are the tags to delete on the remote
+	RemoteTags []string
+	// Reset is the commit the branch is reset to, empty when no commits were created
+	Reset string
+	// Skipped are the tags, which are not deleted, because they were deleted or moved since
+	Skipped []string
+	// Blockers are the reasons, why the entry should not be undone (e.g. the commit was pushed).
+	// Undoing anyway needs to be forced.
+	Blockers []string
+}
+
+// PlanUndo computes, how the entry is undone without changing the repository.
+// The repository must not contain the tags of a remote, since only local tags are deleted locally.
+// When the remote is set, the tags of the entry are deleted on the remote as well.
+// An error is returned, when the entry cannot be undone at all (e.g. because of uncommitted changes
+// or a different checked out branch).
+func PlanUndo(r repository.Repository, entry *Entry, remote string) (Undo, error) {
+	result := Undo{}
+
+	localTags, err := r.TagCommits()
+	if err != nil {
+		return Undo{}, fmt.Errorf("failed to fetch git tags: %v", err)
+	}
+	for _, tag := range entry.Tags {
+		if localTags[tag.Name] == tag.Commit {
+			result.Tags = append(result.Tags, tag.Name)
+		} else {
+			result.Skipped = append(result.Skipped, tag.Name)
+		}
+	}
+
+	if remote != "" {
+		remoteTags, err := r.RemoteTags(remote)
+		if err != nil {
+			return Undo{}, fmt.Errorf("failed to list the tags of %s: %v", remote, err)
+		}
+		for _, tag := range entry.Tags {
+			if remoteHash, ok := remoteTags[tag.Name]; ok {
+				if remoteHash != tag.Commit {
+					return Undo{}, fmt.Errorf("tag %s points to a different commit on %s", tag.Name, remote)
+				}
+				result.RemoteTags = append(result.RemoteTags, tag.Name)
+				result.Blockers = append(result.Blockers, fmt.Sprintf("tag %s was already pushed to %s", tag.Name, remote))
+			}
+		}
+	}
+
+	if len(entry.Commits) == 0 {
+		return result, nil
+	}
+
+	// Resetting another branch to the HEAD of the release would drop its commits
+	branch, err := r.Branch()
+	if err != nil {
+		return Undo{}, fmt.Errorf("failed to get the current branch: %v", err)
+	}
+	if branch != entry.Branch {
+		return Undo{}, fmt.Errorf("cannot reset the release commit, because it was created on %s, not on %s", branchName(entry.Branch), branchName(branch))
+	}
+
+	uncommittedChanges, err := r.HasUncommittedChanges()
+	if err != nil {
+		return Undo{}, fmt.Errorf("failed to check, if uncommitted changes exist: %v", err)
+	}
+	if uncommittedChanges {
+		return Undo{}, fmt.Errorf("cannot reset the release commit, because there are uncommitted changes")
+	}
+
+	last := entry.Commits[len(entry.Commits)-1]
+	head, err := r.Resolve("HEAD")
+	if err != nil {
+		return Undo{}, err
+	}
+	if head != last {
+		result.Blockers = append(result.Blockers, fmt.Sprintf("HEAD has moved since the release commit %s (e.g. new commits on top)", shortHash(last)))
+	}
+	pushed, err := r.Pushed(last)
+	if err != nil {
+		return Undo{}, fmt.Errorf("failed to check, if %s was pushed: %v", shortHash(last), err)
+	}
+	if pushed {
+		result.Blockers = append(result.Blockers, fmt.Sprintf("the release commit %s was already pushed", shortHash(last)))
+	}
+
+	result.Reset = entry.Head
+	return result, nil
+}
+
+// Execute undoes the entry as planned and marks it as undone.
+// The remote tags are deleted first, because it is the step most likely to fail.
+func (u Undo) Execute(r repository.Repository, entry *Entry, remote string) error {
+	for _, name := range u.RemoteTags {
+		err := r.DeleteRemoteTag(remote, name)
+		if err != nil {
+			return fmt.Errorf("failed to delete tag %s on %s: %v", name, remote, err)
+		}
+	}
+
+	for _, name := range u.Tags {
+		err := r.DeleteTag(name)
+		if err != nil {
+			return fmt.Errorf("failed to delete tag %s: %v", name, err)
+		}
+	}
+
+	if u.Reset != "" {
+		err := r.ResetHard(u.Reset)
+		if err != nil {
+			return fmt.Errorf("failed to reset to %s: %v", shortHash(u.Reset), err)
+		}
+	}
+
+	entry.Undone = true
+	return nil
+}
+
+func branchName(branch string) string {
+	if branch == "" {
+		return "a detached HEAD"
+	}
+	return "branch " + branch
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
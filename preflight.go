@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/MatthiasSchild/tagger/version"
+)
+
+// Rules of the pre-flight checks, each can be skipped with --force-<rule>
+const (
+	ruleBranch    = "branch"
+	ruleDetached  = "detached"
+	ruleBehind    = "behind"
+	ruleTagged    = "tagged"
+	ruleUnchanged = "unchanged"
+)
+
+// preflightRules contains the rules, which can be enabled in the config file.
+// The branch rule is enabled by configuring the allowed branches.
+var preflightRules = []string{ruleDetached, ruleBehind, ruleTagged, ruleUnchanged}
+
+// preflightEnabled checks, if the rule is enabled in the config file and not skipped with its flag
+func preflightEnabled(rule string) bool {
+	forced := map[string]bool{
+		ruleBranch:    flagForceBranch,
+		ruleDetached:  flagForceDetached,
+		ruleBehind:    flagForceBehind,
+		ruleTagged:    flagForceTagged,
+		ruleUnchanged: flagForceUnchanged,
+	}
+	if forced[rule] {
+		return false
+	}
+	if rule == ruleBranch {
+		return len(config.Preflight.Branches) > 0
+	}
+	return slices.Contains(config.Preflight.Rules, rule)
+}
+
+// preflightFailure is a failed rule of the pre-flight checks
+type preflightFailure struct {
+	Rule    string
+	Message string
+}
+
+// runPreflight checks the enabled rules before releasing.
+// All failures are reported together, latestTag is nil when no tags exist yet.
+func runPreflight(latestTag *Tag) error {
+	failures, err := checkPreflight(latestTag)
+	if err != nil {
+		return err
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		messages = append(messages, fmt.Sprintf("%s (skip with --force-%s)", failure.Message, failure.Rule))
+	}
+	return fmt.Errorf("pre-flight checks failed:\n  - %s", strings.Join(messages, "\n  - "))
+}
+
+// checkPreflight returns the failed rules of the enabled ones, see runPreflight
+func checkPreflight(latestTag *Tag) ([]preflightFailure, error) {
+	failures := make([]preflightFailure, 0)
+	fail := func(rule string, format string, args ...any) {
+		failures = append(failures, preflightFailure{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	branch := ""
+	if preflightEnabled(ruleBranch) || preflightEnabled(ruleDetached) {
+		var err error
+		branch, err = repo.Branch()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the current branch: %v", err)
+		}
+	}
+
+	if preflightEnabled(ruleBranch) {
+		allowed := slices.ContainsFunc(config.Preflight.Branches, func(pattern string) bool {
+			matched, _ := path.Match(pattern, branch)
+			return matched
+		})
+		if branch == "" {
+			fail(ruleBranch, "HEAD is not on a branch, allowed are %s", strings.Join(config.Preflight.Branches, ", "))
+		} else if !allowed {
+			fail(ruleBranch, "branch %s is not allowed, allowed are %s", branch, strings.Join(config.Preflight.Branches, ", "))
+		}
+	}
+
+	if preflightEnabled(ruleDetached) && branch == "" {
+		fail(ruleDetached, "HEAD is detached")
+	}
+
+	if preflightEnabled(ruleBehind) {
+		upstream, behind, err := repo.Behind()
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare with the upstream: %v", err)
+		}
+		if behind > 0 {
+			fail(ruleBehind, "HEAD is %d commit(s) behind %s", behind, upstream)
+		}
+	}
+
+	if preflightEnabled(ruleTagged) {
+		revision := tagRevision()
+		revisionTags, err := getGitTagsAt(revision)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch git tags of %s: %v", revision, err)
+		}
+		if len(revisionTags) > 0 {
+			fail(ruleTagged, "%s is already tagged with %s", revision, version.Latest(revisionTags))
+		}
+	}
+
+	if preflightEnabled(ruleUnchanged) && latestTag != nil {
+		// Within a component, only the commits touching its path are counted
+		name, err := getGitTagName(*latestTag)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the tag of %s: %v", latestTag, err)
+		}
+		count, err := countCommitsSince(name, ".")
+		if err != nil {
+			return nil, fmt.Errorf("failed to count the commits since %s: %v", latestTag, err)
+		}
+		if count == 0 {
+			fail(ruleUnchanged, "there are no commits since %s", latestTag)
+		}
+	}
+
+	return failures, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/MatthiasSchild/tagger/repository"
+	"github.com/MatthiasSchild/tagger/version"
+)
+
+func TestCheckPreflight(t *testing.T) {
+	memory := repository.NewMemory()
+	tagged := memory.Commit("feat: first")
+	// Tags created with --hash contain the commit, so their name differs from the version
+	if err := memory.CreateTag("v1.2.3-abcdef", tagged, ""); err != nil {
+		t.Fatal(err)
+	}
+	repo = memory
+	tagPrefix = version.DefaultPrefix
+	config = Config{Preflight: PreflightConfig{
+		Branches: []string{"main", "release/*"},
+		Rules:    []string{ruleDetached, ruleBehind, ruleTagged, ruleUnchanged},
+	}}
+	latest := Tag{Major: 1, Minor: 2, Patch: 3}
+
+	rules := func() []string {
+		t.Helper()
+		failures, err := checkPreflight(&latest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := make([]string, 0, len(failures))
+		for _, failure := range failures {
+			result = append(result, failure.Rule)
+		}
+		return result
+	}
+
+	if failed := rules(); len(failed) != 2 || failed[0] != ruleTagged || failed[1] != ruleUnchanged {
+		t.Errorf("expected the tagged and unchanged rules to fail on the tagged commit, got %v", failed)
+	}
+
+	memory.Commit("fix: second")
+	if failed := rules(); len(failed) != 0 {
+		t.Errorf("expected no failures after a new commit, got %v", failed)
+	}
+
+	memory.SetBranch("feature/x")
+	memory.SetUpstream("origin/feature/x", 2)
+	if failed := rules(); len(failed) != 2 || failed[0] != ruleBranch || failed[1] != ruleBehind {
+		t.Errorf("expected the branch and behind rules to fail, got %v", failed)
+	}
+
+	memory.SetBranch("")
+	flagForceBehind = true
+	defer func() { flagForceBehind = false }()
+	if failed := rules(); len(failed) != 2 || failed[0] != ruleBranch || failed[1] != ruleDetached {
+		t.Errorf("expected the branch and detached rules to fail, got %v", failed)
+	}
+}
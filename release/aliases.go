@@ -0,0 +1,48 @@
+package release
+
+import (
+	"fmt"
+
+	"github.com/MatthiasSchild/tagger/repository"
+	"github.com/MatthiasSchild/tagger/version"
+)
+
+// Kinds of the floating alias tags, see Options.Aliases
+const (
+	AliasesLightweight = "lightweight"
+	AliasesAnnotated   = "annotated"
+)
+
+// Aliases returns the floating alias tags (e.g. v1 and v1.2 for v1.2.3), which are moved to the version.
+// An alias only follows the highest version of its line, so a fix of an older line
+// (e.g. v1.2.5 after v1.3.0) only moves v1.2. Versions with a minus addition (e.g. v1.3.0-rc1) move no aliases.
+// The index contains the tags with the prefix of the version, it may already contain the version.
+func Aliases(index *version.Index, tag version.Tag) []string {
+	result := make([]string, 0, 2)
+	if tag.MinusAddition != "" {
+		return result
+	}
+	if index.HighestInMajor(tag) {
+		result = append(result, tag.MajorAlias())
+	}
+	if index.HighestInMinor(tag) {
+		result = append(result, tag.MinorAlias())
+	}
+	return result
+}
+
+// MoveAliases points the alias tags at the commit of the revision, replacing the existing ones.
+// The kind is AliasesLightweight or AliasesAnnotated.
+func MoveAliases(r repository.Repository, names []string, revision string, kind string) error {
+	for _, name := range names {
+		message := ""
+		if kind == AliasesAnnotated {
+			message = name
+		}
+		err := r.MoveTag(name, revision, message)
+		if err != nil {
+			return fmt.Errorf("failed to move alias %s: %v", name, err)
+		}
+	}
+	return nil
+}
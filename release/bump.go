@@ -0,0 +1,32 @@
+package release
+
+import (
+	"regexp"
+	"strings"
+)
+
+// conventionalHeaderRegex matches the header of a conventional commit (e.g. "feat(api)!: add endpoint").
+// The groups are the type and the breaking change marker.
+var conventionalHeaderRegex = regexp.MustCompile(`^(\w+)(?:\([^)]*\))?(!)?:`)
+
+// SuggestBump suggests the part to increase from the commit messages, following conventional commits:
+// major for breaking changes ("feat!:" or a "BREAKING CHANGE:" footer), minor for features ("feat:")
+// and patch for everything else. Without messages, the result is empty.
+func SuggestBump(messages []string) string {
+	result := ""
+	for _, message := range messages {
+		header, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+		groups := conventionalHeaderRegex.FindStringSubmatch(header)
+
+		switch {
+		case groups != nil && groups[2] == "!",
+			strings.Contains(message, "BREAKING CHANGE:"), strings.Contains(message, "BREAKING-CHANGE:"):
+			return "major"
+		case groups != nil && groups[1] == "feat":
+			result = "minor"
+		case result == "":
+			result = "patch"
+		}
+	}
+	return result
+}
@@ -0,0 +1,233 @@
+// Package release runs the release pipeline of tagger on a repository:
+// the next version is computed from the tags, written into the version files, committed and tagged.
+package release
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MatthiasSchild/tagger/repository"
+	"github.com/MatthiasSchild/tagger/targets"
+	"github.com/MatthiasSchild/tagger/version"
+)
+
+// Points of the release, at which Options.Hook is called
+const (
+	// HookPreBump is called before anything is changed
+	HookPreBump = "pre-bump"
+	// HookPostWrite is called after the version was written, before the release commit is created.
+	// It is only called, when a target is written.
+	HookPostWrite = "post-write"
+	// HookPreTag is called before the tag is created
+	HookPreTag = "pre-tag"
+	// HookPostTag is called after the tag was created
+	HookPostTag = "post-tag"
+)
+
+// Options configure a release
+type Options struct {
+	// Bump is the part to increase: major, minor, patch or empty to keep the version
+	Bump string
+	// DateTime sets minor and patch to the seconds of the day and the days since 1970
+	DateTime bool
+	// Hash is the number of characters of the commit hash appended to the version (0 disables it)
+	Hash int
+	// Prefix is the prefix of the version tags, empty for version.DefaultPrefix
+	Prefix string
+	// Initial is the first version, which is used unchanged when no tags with the prefix exist.
+	// When it is nil, a repository without tags results in an error.
+	Initial *version.Tag
+	// Write is the target the version is written to before tagging (empty for none)
+	Write string
+	// Targets contains the settings of the targets
+	Targets targets.Options
+	// Hook is called at the hook points of the release (e.g. HookPreBump), an error aborts the release.
+	// Files changed by the post-write hook are included in the release commit.
+	Hook func(point string, result Result) error
+	// Commit is the revision to tag, empty for HEAD.
+	// The previous version is the latest tag of its ancestors and the version files are not written.
+	Commit string
+	// Aliases is the kind of the floating alias tags moved to the new version (see Aliases):
+	// AliasesLightweight, AliasesAnnotated or empty to not move them
+	Aliases string
+	// Dry computes the release without changing anything
+	Dry bool
+}
+
+// Result is the outcome of a release
+type Result struct {
+	Previous version.Tag
+	Next     version.Tag
+	// Initial is set, when no tags existed and Next is the initial version
+	Initial bool
+	// Aliases are the alias tags moved to Next, when Options.Aliases is set
+	Aliases []string
+}
+
+// Run runs the release pipeline on the repository:
+// the next version is computed from the tags, written to the target, committed and tagged.
+func Run(r repository.Repository, options Options) (Result, error) {
+	result, err := Plan(r, options)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if options.Dry {
+		return result, nil
+	}
+	return result, Execute(r, result, options)
+}
+
+// Plan computes the next version without changing the repository
+func Plan(r repository.Repository, options Options) (Result, error) {
+	names, err := r.Tags()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to fetch git tags: %v", err)
+	}
+	mergedNames := names
+	if options.Commit != "" {
+		mergedNames, err = r.MergedTags(options.Commit)
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to fetch git tags of %s: %v", options.Commit, err)
+		}
+	}
+
+	prefix := options.Prefix
+	if prefix == "" {
+		prefix = version.DefaultPrefix
+	}
+	result := Result{}
+	latestTag, ok := version.NewIndex(mergedNames, prefix).Latest()
+	if ok {
+		result.Previous = latestTag
+		result.Next = latestTag.Bump(options.Bump)
+	} else if options.Initial != nil {
+		result.Next = options.Initial.Clone()
+		result.Next.Prefix = prefix
+		result.Initial = true
+	} else {
+		return Result{}, fmt.Errorf("no tags found")
+	}
+
+	newTag := result.Next
+	if options.DateTime {
+		now := time.Now().Unix()
+		newTag.Minor = int(now % (60 * 60 * 24))
+		newTag.Patch = int(now / (60 * 60 * 24))
+	}
+
+	if options.Hash != 0 {
+		hash, err := r.Resolve(revision(options))
+		if err != nil {
+			return Result{}, fmt.Errorf("could not get current hash: %v", err)
+		}
+		newTag.MinusAddition = hash[:options.Hash]
+	}
+
+	// Tags of other lines of development (e.g. a later release) can already contain the version
+	index := version.NewIndex(names, prefix)
+	if options.Commit != "" && index.Contains(newTag) {
+		return Result{}, fmt.Errorf("the next version %s of %s is already tagged", newTag, options.Commit)
+	}
+	if options.Aliases != "" {
+		result.Aliases = Aliases(index, newTag)
+	}
+
+	result.Next = newTag
+	return result, nil
+}
+
+// revision returns the revision to tag
+func revision(options Options) string {
+	if options.Commit == "" {
+		return "HEAD"
+	}
+	return options.Commit
+}
+
+// Execute writes the version to the target, commits the change and creates the tag of the planned release.
+// When the write step or the post-write hook fails, the changes of tracked files are discarded.
+// When the pre-tag hook fails, the release commit is dropped as well.
+func Execute(r repository.Repository, result Result, options Options) error {
+	hook := func(point string) error {
+		if options.Hook == nil {
+			return nil
+		}
+		err := options.Hook(point, result)
+		if err != nil {
+			return fmt.Errorf("%s hook failed: %v", point, err)
+		}
+		return nil
+	}
+	newTag := result.Next
+	if options.Commit != "" && options.Write != "" {
+		return fmt.Errorf("the version files can only be written, when HEAD is tagged")
+	}
+
+	// Changes made by the pre-bump hook belong to the release commit, so they are not checked
+	if options.Write != "" {
+		uncommittedChanges, err := r.HasUncommittedChanges()
+		if err != nil {
+			return fmt.Errorf("failed to check, if uncommitted changes exist: %v", err)
+		}
+		if uncommittedChanges {
+			return fmt.Errorf("cannot use 'write' flag, because there are uncommitted changes")
+		}
+	}
+
+	err := hook(HookPreBump)
+	if err != nil {
+		return err
+	}
+
+	if options.Write != "" {
+		targetOptions := options.Targets
+		if options.Write == "generate" && targetOptions.Commit == "" {
+			targetOptions.Commit, err = r.Resolve("HEAD")
+			if err != nil {
+				return fmt.Errorf("could not get current hash: %v", err)
+			}
+		}
+		err = targets.Write(options.Write, newTag, targetOptions)
+		if err == nil {
+			err = hook(HookPostWrite)
+		}
+		if err != nil {
+			if resetErr := r.ResetHard("HEAD"); resetErr != nil {
+				return fmt.Errorf("%v, discarding the changes failed as well: %v", err, resetErr)
+			}
+			return err
+		}
+		head, err := r.Resolve("HEAD")
+		if err != nil {
+			return fmt.Errorf("could not get current hash: %v", err)
+		}
+		err = r.CommitAll(newTag.String())
+		if err != nil {
+			return fmt.Errorf("failed to create commit: %v", err)
+		}
+
+		// An untagged release commit must not stay on the branch
+		err = hook(HookPreTag)
+		if err != nil {
+			if resetErr := r.ResetHard(head); resetErr != nil {
+				return fmt.Errorf("%v, dropping the release commit failed as well: %v", err, resetErr)
+			}
+			return err
+		}
+	} else {
+		err = hook(HookPreTag)
+		if err != nil {
+			return err
+		}
+	}
+	err = r.CreateTag(newTag.String(), revision(options), newTag.String())
+	if err != nil {
+		return fmt.Errorf("failed to create tag: %v", err)
+	}
+	err = MoveAliases(r, result.Aliases, revision(options), options.Aliases)
+	if err != nil {
+		return err
+	}
+	return hook(HookPostTag)
+}
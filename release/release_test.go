@@ -0,0 +1,241 @@
+package release_test
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/MatthiasSchild/tagger/release"
+	"github.com/MatthiasSchild/tagger/repository"
+	"github.com/MatthiasSchild/tagger/version"
+)
+
+func TestRun(t *testing.T) {
+	repo := repository.NewMemory()
+	_ = repo.CreateTag("v1.2.3", "HEAD", "v1.2.3")
+	repo.Commit("feature")
+
+	result, err := release.Run(repo, release.Options{Bump: "minor", Dry: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Previous.String() != "v1.2.3" || result.Next.String() != "v1.3.0" {
+		t.Errorf("unexpected release %s -> %s", result.Previous, result.Next)
+	}
+	tags, _ := repo.TagsAt("HEAD")
+	if len(tags) != 0 {
+		t.Errorf("dry run should not tag, got %v", tags)
+	}
+
+	result, err = release.Run(repo, release.Options{Bump: "patch", Hash: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, _ := repo.Resolve("HEAD")
+	if result.Next.String() != "v1.2.4-"+head[:7] {
+		t.Errorf("unexpected next version %s", result.Next)
+	}
+	tags, _ = repo.TagsAt("HEAD")
+	if len(tags) != 1 || tags[0] != result.Next.String() {
+		t.Errorf("expected HEAD to be tagged with %s, got %v", result.Next, tags)
+	}
+}
+
+func TestRunWithPrefix(t *testing.T) {
+	repo := repository.NewMemory()
+	_ = repo.CreateTag("v2.0.0", "HEAD", "v2.0.0")
+	_ = repo.CreateTag("api/v0.4.1", "HEAD", "api/v0.4.1")
+
+	result, err := release.Run(repo, release.Options{Bump: "major", Prefix: "api/v"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Next.String() != "api/v1.0.0" {
+		t.Errorf("unexpected next version %s", result.Next)
+	}
+}
+
+func TestRunWithoutTags(t *testing.T) {
+	repo := repository.NewMemory()
+
+	_, err := release.Run(repo, release.Options{Bump: "patch"})
+	if err == nil || !strings.Contains(err.Error(), "no tags found") {
+		t.Errorf("expected missing tags to fail, got %v", err)
+	}
+}
+
+func TestRunWithInitial(t *testing.T) {
+	repo := repository.NewMemory()
+	_ = repo.CreateTag("api/v3.0.0", "HEAD", "api/v3.0.0")
+	initial := version.Tag{Major: 0, Minor: 1, Patch: 0}
+
+	result, err := release.Run(repo, release.Options{Bump: "minor", Initial: &initial})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Initial || result.Next.String() != "v0.1.0" {
+		t.Errorf("expected the initial version v0.1.0 unchanged, got %s", result.Next)
+	}
+
+	result, err = release.Run(repo, release.Options{Bump: "minor", Initial: &initial})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Initial || result.Next.String() != "v0.2.0" {
+		t.Errorf("expected v0.2.0 after the initial version, got %s", result.Next)
+	}
+}
+
+func TestRunWithHooks(t *testing.T) {
+	repo := repository.NewMemory()
+	_ = repo.CreateTag("v1.0.0", "HEAD", "v1.0.0")
+
+	points := make([]string, 0)
+	options := release.Options{Bump: "patch", Hook: func(point string, result release.Result) error {
+		points = append(points, point+" "+result.Next.String())
+		if point == release.HookPreBump && result.Next.Patch > 1 {
+			return errors.New("tests failed")
+		}
+		return nil
+	}}
+
+	_, err := release.Run(repo, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"pre-bump v1.0.1", "pre-tag v1.0.1", "post-tag v1.0.1"}
+	if !slices.Equal(points, expected) {
+		t.Errorf("unexpected hooks %v", points)
+	}
+
+	repo.Commit("feature")
+	_, err = release.Run(repo, options)
+	if err == nil || !strings.Contains(err.Error(), "pre-bump hook failed: tests failed") {
+		t.Errorf("expected the pre-bump hook to abort, got %v", err)
+	}
+	if tags, _ := repo.Tags(); len(tags) != 2 {
+		t.Errorf("the aborted release should not be tagged, got %v", tags)
+	}
+}
+
+func TestRunWithFailingPreTagHook(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("package.json", []byte(`{"version": "1.0.0"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo := repository.NewMemory()
+	_ = repo.CreateTag("v1.0.0", "HEAD", "v1.0.0")
+	before, _ := repo.Resolve("HEAD")
+
+	options := release.Options{Bump: "patch", Write: "npm", Hook: func(point string, result release.Result) error {
+		if point == release.HookPreTag {
+			return errors.New("signing failed")
+		}
+		return nil
+	}}
+	_, err := release.Run(repo, options)
+	if err == nil || !strings.Contains(err.Error(), "pre-tag hook failed: signing failed") {
+		t.Errorf("expected the pre-tag hook to abort, got %v", err)
+	}
+	if head, _ := repo.Resolve("HEAD"); head != before {
+		t.Error("the untagged release commit should be dropped")
+	}
+	if tags, _ := repo.Tags(); len(tags) != 1 {
+		t.Errorf("the aborted release should not be tagged, got %v", tags)
+	}
+}
+
+func TestRunWithUncommittedChanges(t *testing.T) {
+	repo := repository.NewMemory()
+	_ = repo.CreateTag("v1.0.0", "HEAD", "v1.0.0")
+	repo.Change("package.json")
+
+	_, err := release.Run(repo, release.Options{Bump: "patch", Write: "npm"})
+	if err == nil || !strings.Contains(err.Error(), "uncommitted changes") {
+		t.Errorf("expected uncommitted changes to fail, got %v", err)
+	}
+}
+
+func TestRunWithCommit(t *testing.T) {
+	repo := repository.NewMemory()
+	_ = repo.CreateTag("v1.0.0", "HEAD", "v1.0.0")
+	validated := repo.Commit("validated by CI")
+	repo.Commit("later feature")
+	_ = repo.CreateTag("v1.1.0", "HEAD", "v1.1.0")
+	repo.Commit("work in progress")
+
+	result, err := release.Run(repo, release.Options{Bump: "patch", Commit: validated})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Previous.String() != "v1.0.0" || result.Next.String() != "v1.0.1" {
+		t.Errorf("unexpected release %s -> %s", result.Previous, result.Next)
+	}
+	tags, _ := repo.TagsAt(validated)
+	if !slices.Equal(tags, []string{"v1.0.1"}) {
+		t.Errorf("the commit should be tagged, got %v", tags)
+	}
+
+	_, err = release.Run(repo, release.Options{Bump: "minor", Commit: validated})
+	if err == nil || !strings.Contains(err.Error(), "already tagged") {
+		t.Errorf("expected v1.1.0 to conflict with the existing tag, got %v", err)
+	}
+	_, err = release.Run(repo, release.Options{Bump: "major", Commit: validated, Write: "npm"})
+	if err == nil {
+		t.Error("writing the version files of another commit should fail")
+	}
+}
+
+func TestRunWithAliases(t *testing.T) {
+	repo := repository.NewMemory()
+	_ = repo.CreateTag("v1.2.0", "HEAD", "v1.2.0")
+	_ = repo.MoveTag("v1", "HEAD", "")
+	_ = repo.MoveTag("v1.2", "HEAD", "")
+	fix := repo.Commit("fix")
+	repo.Commit("feature")
+
+	result, err := release.Run(repo, release.Options{Bump: "minor", Aliases: release.AliasesLightweight})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Next.String() != "v1.3.0" || !slices.Equal(result.Aliases, []string{"v1", "v1.3"}) {
+		t.Errorf("unexpected release %s with aliases %v", result.Next, result.Aliases)
+	}
+	tags, _ := repo.TagsAt("HEAD")
+	slices.Sort(tags)
+	if !slices.Equal(tags, []string{"v1", "v1.3", "v1.3.0"}) {
+		t.Errorf("the aliases should point at the release, got %v", tags)
+	}
+
+	result, err = release.Run(repo, release.Options{Bump: "patch", Commit: fix, Aliases: release.AliasesAnnotated})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Next.String() != "v1.2.1" || !slices.Equal(result.Aliases, []string{"v1.2"}) {
+		t.Errorf("a fix of an older line should only move its minor alias, got %s with %v", result.Next, result.Aliases)
+	}
+	if commits, _ := repo.TagCommits(); commits["v1"] == fix || commits["v1.2"] != fix {
+		t.Errorf("unexpected aliases after the fix %v", commits)
+	}
+}
+
+func TestSuggestBump(t *testing.T) {
+	tests := []struct {
+		messages []string
+		expected string
+	}{
+		{nil, ""},
+		{[]string{"fix: typo", "update readme"}, "patch"},
+		{[]string{"fix: typo", "feat(api): add endpoint", "chore: deps"}, "minor"},
+		{[]string{"feat(api)!: remove endpoint", "fix: typo"}, "major"},
+		{[]string{"refactor: config\n\nBREAKING CHANGE: the prefix option is removed"}, "major"},
+	}
+
+	for _, test := range tests {
+		if bump := release.SuggestBump(test.messages); bump != test.expected {
+			t.Errorf("expected %q for %q, got %q", test.expected, test.messages, bump)
+		}
+	}
+}
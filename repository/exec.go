@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// execRepository runs the git binary for every operation
+type execRepository struct {
+	dir string
+}
+
+// NewExec returns a repository, which runs the git binary within the directory
+func NewExec(dir string) Repository {
+	return &execRepository{dir: dir}
+}
+
+func (r *execRepository) run(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func (r *execRepository) Tags() ([]string, error) {
+	out, err := r.run("for-each-ref", "--format=%(refname:strip=2)", "refs/tags")
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(out), nil
+}
+
+func (r *execRepository) TagsAt(revision string) ([]string, error) {
+	out, err := r.run("tag", "--points-at", revision)
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(out), nil
+}
+
+func (r *execRepository) MergedTags(revision string) ([]string, error) {
+	out, err := r.run("tag", "--merged", revision)
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(out), nil
+}
+
+func (r *execRepository) TagCommits() (map[string]string, error) {
+	// %(*objectname) is the peeled commit of annotated tags and empty for lightweight ones
+	out, err := r.run("for-each-ref", "--format=%(refname:strip=2) %(objectname) %(*objectname)", "refs/tags")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string)
+	for _, line := range splitLines(out) {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		result[fields[0]] = fields[len(fields)-1]
+	}
+	return result, nil
+}
+
+func (r *execRepository) RemoteTags(remote string) (map[string]string, error) {
+	return lsRemoteTags(r.dir, remote)
+}
+
+func (r *execRepository) Resolve(revision string) (string, error) {
+	out, err := r.run("rev-parse", "--verify", revision+"^{commit}")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+func (r *execRepository) Branch() (string, error) {
+	out, err := r.run("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	branch := strings.TrimSpace(out)
+	if branch == "HEAD" {
+		return "", nil
+	}
+	return branch, nil
+}
+
+func (r *execRepository) Behind() (string, int, error) {
+	// rev-parse fails, when no upstream is configured (or HEAD is detached)
+	out, err := r.run("rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+	if err != nil {
+		return "", 0, nil
+	}
+	upstream := strings.TrimSpace(out)
+
+	out, err = r.run("rev-list", "--count", "HEAD..@{upstream}")
+	if err != nil {
+		return "", 0, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(out))
+	return upstream, count, err
+}
+
+func (r *execRepository) CreateTag(name string, revision string, message string) error {
+	_, err := r.run("tag", "-a", name, "-m", message, revision)
+	return err
+}
+
+func (r *execRepository) MoveTag(name string, revision string, message string) error {
+	if message == "" {
+		_, err := r.run("tag", "--force", name, revision)
+		return err
+	}
+	_, err := r.run("tag", "--force", "-a", name, "-m", message, revision)
+	return err
+}
+
+func (r *execRepository) DeleteTag(name string) error {
+	_, err := r.run("tag", "-d", name)
+	return err
+}
+
+func (r *execRepository) CommitAll(message string) error {
+	_, err := r.run("add", "--all")
+	if err != nil {
+		return err
+	}
+	_, err = r.run("commit", "-m", message)
+	return err
+}
+
+func (r *execRepository) HasUncommittedChanges() (bool, error) {
+	out, err := r.run("diff", "HEAD")
+	if err != nil {
+		return false, err
+	}
+	return len(strings.TrimSpace(out)) > 0, nil
+}
+
+func (r *execRepository) CountCommits(since string, revision string, path string) (int, error) {
+	if since != "" {
+		revision = since + ".." + revision
+	}
+	args := []string{"rev-list", "--count", revision}
+	if path != "" {
+		args = append(args, "--", path)
+	}
+
+	out, err := r.run(args...)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(out))
+}
+
+func (r *execRepository) Messages(since string, revision string) ([]string, error) {
+	if since != "" {
+		revision = since + ".." + revision
+	}
+	out, err := r.run("log", "--format=%B%x00", revision)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0)
+	for _, message := range strings.Split(out, "\x00") {
+		message = strings.TrimLeft(message, "\n")
+		if message != "" {
+			result = append(result, message)
+		}
+	}
+	return result, nil
+}
+
+func (r *execRepository) Commits(revision string, path string) ([]string, error) {
+	args := []string{"rev-list", "--first-parent", revision}
+	if path != "" {
+		args = append(args, "--", path)
+	}
+
+	out, err := r.run(args...)
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(out), nil
+}
+
+func (r *execRepository) Files(revision string) (fs.FS, error) {
+	// Without --full-tree, only the files below the working directory are listed relative to it
+	out, err := r.run("ls-tree", "-r", "-z", revision)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string)
+	for _, line := range strings.Split(out, "\x00") {
+		info, path, ok := strings.Cut(line, "\t")
+		fields := strings.Fields(info)
+		if !ok || len(fields) != 3 || fields[1] != "blob" {
+			continue
+		}
+		files[path] = fields[2]
+	}
+
+	return &treeFS{files: files, read: func(hash string) ([]byte, error) {
+		out, err := r.run("cat-file", "blob", hash)
+		return []byte(out), err
+	}}, nil
+}
+
+func (r *execRepository) ResetHard(revision string) error {
+	_, err := r.run("reset", "--hard", revision)
+	return err
+}
+
+func (r *execRepository) Pushed(hash string) (bool, error) {
+	out, err := r.run("branch", "--remotes", "--contains", hash)
+	if err != nil {
+		return false, err
+	}
+	return len(splitLines(out)) > 0, nil
+}
+
+func (r *execRepository) DeleteRemoteTag(remote string, name string) error {
+	return pushDeleteTag(r.dir, remote, name)
+}
+
+func (r *execRepository) GitDir() string {
+	out, err := r.run("rev-parse", "--git-common-dir")
+	if err != nil {
+		return ""
+	}
+	dir := strings.TrimSpace(out)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(r.dir, dir)
+	}
+	return dir
+}
+
+// pushDeleteTag deletes a tag of a remote with "git push --delete"
+func pushDeleteTag(dir string, remote string, name string) error {
+	cmd := exec.Command("git", "push", "--quiet", remote, "--delete", "refs/tags/"+name)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git push failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// lsRemoteTags lists the tags of a remote with "git ls-remote".
+// The peeled entries of annotated tags (ending with ^{}) replace the hash of the tag object.
+func lsRemoteTags(dir string, remote string) (map[string]string, error) {
+	cmd := exec.Command("git", "ls-remote", "--tags", remote)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("git ls-remote failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+
+	result := make(map[string]string)
+	peeled := make(map[string]bool)
+	for _, line := range splitLines(string(out)) {
+		hash, ref, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		name, isTag := strings.CutPrefix(ref, "refs/tags/")
+		if !isTag {
+			continue
+		}
+		if base, isPeeled := strings.CutSuffix(name, "^{}"); isPeeled {
+			result[base] = hash
+			peeled[base] = true
+		} else if !peeled[name] {
+			result[name] = hash
+		}
+	}
+	return result, nil
+}
+
+func splitLines(out string) []string {
+	result := make([]string, 0)
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
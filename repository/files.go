@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// treeFS is the read-only file system of the files of a commit.
+// The files map the slash separated paths to a key (e.g. the blob hash), which read turns into the content.
+// Directories only exist implicitly as parents of files, like in git.
+type treeFS struct {
+	files map[string]string
+	read  func(key string) ([]byte, error)
+}
+
+func (t *treeFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if key, ok := t.files[name]; ok {
+		content, err := t.read(key)
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		info := treeFileInfo{name: path.Base(name), size: int64(len(content))}
+		return &treeFile{info: info, reader: bytes.NewReader(content)}, nil
+	}
+
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	}
+	children := make(map[string]bool)
+	for file := range t.files {
+		rest, ok := strings.CutPrefix(file, prefix)
+		if !ok {
+			continue
+		}
+		child, _, isDir := strings.Cut(rest, "/")
+		children[child] = children[child] || isDir
+	}
+	if len(children) == 0 && name != "." {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	entries := make([]fs.DirEntry, 0, len(children))
+	for child, isDir := range children {
+		entries = append(entries, &treeDirEntry{fsys: t, path: prefix + child, dir: isDir})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return &treeDir{info: treeFileInfo{name: path.Base(name), dir: true}, entries: entries}, nil
+}
+
+// treeDirEntry is an entry of a directory of a treeFS, its info is read when it is needed
+type treeDirEntry struct {
+	fsys *treeFS
+	path string
+	dir  bool
+}
+
+func (e *treeDirEntry) Name() string { return path.Base(e.path) }
+func (e *treeDirEntry) IsDir() bool  { return e.dir }
+
+func (e *treeDirEntry) Type() fs.FileMode {
+	if e.dir {
+		return fs.ModeDir
+	}
+	return 0
+}
+
+func (e *treeDirEntry) Info() (fs.FileInfo, error) {
+	return fs.Stat(e.fsys, e.path)
+}
+
+// treeFileInfo describes a file or directory of a treeFS
+type treeFileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i treeFileInfo) Name() string       { return i.name }
+func (i treeFileInfo) Size() int64        { return i.size }
+func (i treeFileInfo) ModTime() time.Time { return time.Time{} }
+func (i treeFileInfo) IsDir() bool        { return i.dir }
+func (i treeFileInfo) Sys() any           { return nil }
+
+func (i treeFileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+// treeFile is an opened file of a treeFS
+type treeFile struct {
+	info   treeFileInfo
+	reader *bytes.Reader
+}
+
+func (f *treeFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *treeFile) Read(b []byte) (int, error) { return f.reader.Read(b) }
+func (f *treeFile) Close() error               { return nil }
+
+// treeDir is an opened directory of a treeFS
+type treeDir struct {
+	info    treeFileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *treeDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *treeDir) Close() error               { return nil }
+
+func (d *treeDir) Read(b []byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+func (d *treeDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if count <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if count > len(rest) {
+		count = len(rest)
+	}
+	d.offset += count
+	return rest[:count], nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// readGitConfig reads the values of the git config files as "section.key",
+// the repository config overrides the global one.
+// Subsections (e.g. [remote "origin"]) are kept as "section.subsection.key".
+func readGitConfig(repositoryConfig string) map[string]string {
+	paths := make([]string, 0)
+
+	home, _ := os.UserHomeDir()
+	xdgHome := os.Getenv("XDG_CONFIG_HOME")
+	if xdgHome == "" && home != "" {
+		xdgHome = filepath.Join(home, ".config")
+	}
+	if xdgHome != "" {
+		paths = append(paths, filepath.Join(xdgHome, "git", "config"))
+	}
+	if global := os.Getenv("GIT_CONFIG_GLOBAL"); global != "" {
+		paths = append(paths, global)
+	} else if home != "" {
+		paths = append(paths, filepath.Join(home, ".gitconfig"))
+	}
+	paths = append(paths, repositoryConfig)
+
+	result := make(map[string]string)
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		parseGitConfig(string(content), result)
+	}
+	return result
+}
+
+func parseGitConfig(content string, result map[string]string) {
+	section := ""
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			end := strings.Index(line, "]")
+			if end < 0 {
+				continue
+			}
+			name, subsection, hasSubsection := strings.Cut(line[1:end], " ")
+			section = strings.ToLower(name)
+			if hasSubsection {
+				section += "." + strings.Trim(strings.TrimSpace(subsection), `"`)
+			}
+			continue
+		}
+
+		key, value, hasValue := strings.Cut(line, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if !hasValue {
+			value = "true"
+		}
+		result[section+"."+key] = parseGitConfigValue(strings.TrimSpace(value))
+	}
+}
+
+// parseGitConfigValue removes quotes and comments of a value
+func parseGitConfigValue(value string) string {
+	result := &strings.Builder{}
+	quoted := false
+
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '"':
+			quoted = !quoted
+		case value[i] == '\\' && i+1 < len(value):
+			i++
+			switch value[i] {
+			case 'n':
+				result.WriteByte('\n')
+			case 't':
+				result.WriteByte('\t')
+			default:
+				result.WriteByte(value[i])
+			}
+		case (value[i] == '#' || value[i] == ';') && !quoted:
+			return strings.TrimSpace(result.String())
+		default:
+			result.WriteByte(value[i])
+		}
+	}
+
+	return result.String()
+}
+
+// signature builds the author or committer line of commits and tags (e.g. "Jane <jane@example.com> 1577867400 +0100").
+// The environment variables GIT_<ROLE>_NAME and GIT_<ROLE>_EMAIL override the config.
+func signature(gitConfig map[string]string, role string) (string, error) {
+	name := os.Getenv("GIT_" + role + "_NAME")
+	if name == "" {
+		name = gitConfig["user.name"]
+	}
+	email := os.Getenv("GIT_" + role + "_EMAIL")
+	if email == "" {
+		email = gitConfig["user.email"]
+	}
+	if name == "" || email == "" {
+		return "", fmt.Errorf("please configure user.name and user.email in your git config")
+	}
+
+	now := time.Now()
+	return fmt.Sprintf("%s <%s> %d %s", name, email, now.Unix(), now.Format("-0700")), nil
+}
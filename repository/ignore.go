@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignorePattern is a single pattern of a .gitignore file
+type ignorePattern struct {
+	base    string
+	regex   *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreMatcher decides, if untracked files are ignored.
+// It supports the global excludes file, .git/info/exclude and the .gitignore files of the work tree.
+type ignoreMatcher struct {
+	patterns []ignorePattern
+}
+
+// globalExcludesFile returns the path of the global excludes file: core.excludesFile of the git config
+// or $XDG_CONFIG_HOME/git/ignore (default ~/.config/git/ignore) like git does
+func globalExcludesFile(gitConfig map[string]string) string {
+	home, _ := os.UserHomeDir()
+	if path := gitConfig["core.excludesfile"]; path != "" {
+		if rest, ok := strings.CutPrefix(path, "~/"); ok && home != "" {
+			return filepath.Join(home, rest)
+		}
+		return path
+	}
+
+	xdgHome := os.Getenv("XDG_CONFIG_HOME")
+	if xdgHome == "" {
+		if home == "" {
+			return ""
+		}
+		xdgHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(xdgHome, "git", "ignore")
+}
+
+// load reads the patterns of an ignore file, which apply to the paths below base.
+// Missing files are skipped.
+func (m *ignoreMatcher) load(path string, base string) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		pattern, ok := compileIgnorePattern(line, base)
+		if ok {
+			m.patterns = append(m.patterns, pattern)
+		}
+	}
+}
+
+// ignored checks, if the slash separated path relative to the work tree is ignored.
+// The last matching pattern decides.
+func (m *ignoreMatcher) ignored(path string, isDir bool) bool {
+	result := false
+
+	for _, pattern := range m.patterns {
+		relative := path
+		if pattern.base != "" {
+			if !strings.HasPrefix(path, pattern.base+"/") {
+				continue
+			}
+			relative = strings.TrimPrefix(path, pattern.base+"/")
+		}
+		if pattern.dirOnly && !isDir {
+			continue
+		}
+		if pattern.regex.MatchString(relative) {
+			result = !pattern.negate
+		}
+	}
+
+	return result
+}
+
+func compileIgnorePattern(line string, base string) (ignorePattern, bool) {
+	line = strings.TrimRight(line, "\r")
+	if !strings.HasSuffix(line, "\\ ") {
+		line = strings.TrimRight(line, " ")
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false
+	}
+
+	pattern := ignorePattern{base: base}
+	if strings.HasPrefix(line, "!") {
+		pattern.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignorePattern{}, false
+	}
+
+	// Patterns with a slash are relative to the directory of the .gitignore,
+	// the others match the name at any depth
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expression := &strings.Builder{}
+	if anchored {
+		expression.WriteString("^")
+	} else {
+		expression.WriteString("^(?:.*/)?")
+	}
+
+	for i := 0; i < len(line); i++ {
+		switch {
+		case strings.HasPrefix(line[i:], "**/"):
+			expression.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(line[i:], "**"):
+			expression.WriteString(".*")
+			i++
+		case line[i] == '*':
+			expression.WriteString("[^/]*")
+		case line[i] == '?':
+			expression.WriteString("[^/]")
+		case line[i] == '[':
+			end := strings.IndexByte(line[i+1:], ']')
+			if end < 0 {
+				expression.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+			class := line[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expression.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case line[i] == '\\' && i+1 < len(line):
+			expression.WriteString(regexp.QuoteMeta(line[i+1 : i+2]))
+			i++
+		default:
+			expression.WriteString(regexp.QuoteMeta(line[i : i+1]))
+		}
+	}
+	expression.WriteString("$")
+
+	regex, err := regexp.Compile(expression.String())
+	if err != nil {
+		return ignorePattern{}, false
+	}
+	pattern.regex = regex
+	return pattern, true
+}
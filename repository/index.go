@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// Flags of the index entries
+const (
+	indexFlagAssumeValid = 0x8000
+	indexFlagExtended    = 0x4000
+	indexFlagStage       = 0x3000
+	indexFlagNameLength  = 0x0fff
+
+	indexExtendedSkipWorktree = 0x4000
+)
+
+// indexEntry is a single file of the index (staging area)
+type indexEntry struct {
+	ctimeSeconds     uint32
+	ctimeNanoseconds uint32
+	mtimeSeconds     uint32
+	mtimeNanoseconds uint32
+	dev              uint32
+	ino              uint32
+	mode             uint32
+	uid              uint32
+	gid              uint32
+	size             uint32
+	hash             string
+	flags            uint16
+	extendedFlags    uint16
+	path             string
+}
+
+// treeMode returns the mode of the entry as it is written into trees
+func (e indexEntry) treeMode() string {
+	return strconv.FormatUint(uint64(e.mode), 8)
+}
+
+func (e indexEntry) stage() int {
+	return int(e.flags&indexFlagStage) >> 12
+}
+
+// setStat updates the stat information of the entry from the file
+func (e *indexEntry) setStat(info os.FileInfo) {
+	mtime := info.ModTime()
+	e.mtimeSeconds = uint32(mtime.Unix())
+	e.mtimeNanoseconds = uint32(mtime.Nanosecond())
+	e.ctimeSeconds = e.mtimeSeconds
+	e.ctimeNanoseconds = e.mtimeNanoseconds
+	e.size = uint32(info.Size())
+	e.dev, e.ino, e.uid, e.gid = 0, 0, 0, 0
+}
+
+// statMatches checks, if the file probably has not been changed since it was staged.
+// Changing only the mode (e.g. chmod +x) keeps the modification time, so the mode is compared as well.
+func (e indexEntry) statMatches(info os.FileInfo) bool {
+	mtime := info.ModTime()
+	return e.mtimeSeconds == uint32(mtime.Unix()) &&
+		e.mtimeNanoseconds == uint32(mtime.Nanosecond()) &&
+		e.size == uint32(info.Size()) &&
+		e.treeMode() == fileMode(info)
+}
+
+// gitIndex is the index file of the repository (version 2 or 3)
+type gitIndex struct {
+	version uint32
+	entries []indexEntry
+}
+
+// readIndex reads the index file, a missing file results in an empty index
+func readIndex(path string) (*gitIndex, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &gitIndex{version: 2}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(content) < 12+20 || string(content[:4]) != "DIRC" {
+		return nil, fmt.Errorf("invalid index file")
+	}
+	checksum := sha1.Sum(content[:len(content)-20])
+	if !bytes.Equal(checksum[:], content[len(content)-20:]) {
+		return nil, fmt.Errorf("index file checksum mismatch")
+	}
+
+	index := &gitIndex{version: binary.BigEndian.Uint32(content[4:8])}
+	if index.version != 2 && index.version != 3 {
+		return nil, fmt.Errorf("unsupported index version %d", index.version)
+	}
+
+	count := int(binary.BigEndian.Uint32(content[8:12]))
+	position := 12
+	body := content[:len(content)-20]
+	for i := 0; i < count; i++ {
+		if len(body) < position+62 {
+			return nil, fmt.Errorf("truncated index file")
+		}
+
+		fields := make([]uint32, 10)
+		for field := range fields {
+			fields[field] = binary.BigEndian.Uint32(body[position+field*4:])
+		}
+		entry := indexEntry{
+			ctimeSeconds:     fields[0],
+			ctimeNanoseconds: fields[1],
+			mtimeSeconds:     fields[2],
+			mtimeNanoseconds: fields[3],
+			dev:              fields[4],
+			ino:              fields[5],
+			mode:             fields[6],
+			uid:              fields[7],
+			gid:              fields[8],
+			size:             fields[9],
+			hash:             hex.EncodeToString(body[position+40 : position+60]),
+			flags:            binary.BigEndian.Uint16(body[position+60:]),
+		}
+
+		start := position
+		position += 62
+		if entry.flags&indexFlagExtended != 0 {
+			if len(body) < position+2 {
+				return nil, fmt.Errorf("truncated index file")
+			}
+			entry.extendedFlags = binary.BigEndian.Uint16(body[position:])
+			position += 2
+		}
+
+		end := bytes.IndexByte(body[position:], 0)
+		if end < 0 {
+			return nil, fmt.Errorf("truncated index file")
+		}
+		entry.path = string(body[position : position+end])
+		position += end + 1
+
+		// Entries are padded with NUL bytes to a multiple of 8
+		for (position-start)%8 != 0 {
+			position++
+		}
+		index.entries = append(index.entries, entry)
+	}
+
+	// Optional extensions (starting with an uppercase letter) are caches, which can be dropped
+	for position+8 <= len(body) {
+		signature := body[position : position+4]
+		size := int(binary.BigEndian.Uint32(body[position+4:]))
+		if signature[0] < 'A' || signature[0] > 'Z' {
+			return nil, fmt.Errorf("unsupported index extension %s", signature)
+		}
+		position += 8 + size
+	}
+
+	return index, nil
+}
+
+// write writes the index file without extensions
+func (idx *gitIndex) write(path string) error {
+	idx.sort()
+
+	result := &bytes.Buffer{}
+	result.WriteString("DIRC")
+	binary.Write(result, binary.BigEndian, idx.version)
+	binary.Write(result, binary.BigEndian, uint32(len(idx.entries)))
+
+	for _, entry := range idx.entries {
+		start := result.Len()
+		for _, field := range []uint32{
+			entry.ctimeSeconds, entry.ctimeNanoseconds,
+			entry.mtimeSeconds, entry.mtimeNanoseconds,
+			entry.dev, entry.ino, entry.mode, entry.uid, entry.gid, entry.size,
+		} {
+			binary.Write(result, binary.BigEndian, field)
+		}
+
+		rawHash, err := hex.DecodeString(entry.hash)
+		if err != nil {
+			return err
+		}
+		result.Write(rawHash)
+
+		nameLength := len(entry.path)
+		if nameLength > indexFlagNameLength {
+			nameLength = indexFlagNameLength
+		}
+		flags := entry.flags&^indexFlagNameLength | uint16(nameLength)
+		if idx.version < 3 || entry.extendedFlags == 0 {
+			flags &^= indexFlagExtended
+		} else {
+			flags |= indexFlagExtended
+		}
+		binary.Write(result, binary.BigEndian, flags)
+		if flags&indexFlagExtended != 0 {
+			binary.Write(result, binary.BigEndian, entry.extendedFlags)
+		}
+
+		result.WriteString(entry.path)
+		result.WriteByte(0)
+		for (result.Len()-start)%8 != 0 {
+			result.WriteByte(0)
+		}
+	}
+
+	checksum := sha1.Sum(result.Bytes())
+	result.Write(checksum[:])
+
+	return writeLockedFile(path, result.Bytes())
+}
+
+func (idx *gitIndex) sort() {
+	sort.SliceStable(idx.entries, func(i, j int) bool {
+		if idx.entries[i].path != idx.entries[j].path {
+			return idx.entries[i].path < idx.entries[j].path
+		}
+		return idx.entries[i].stage() < idx.entries[j].stage()
+	})
+}
+
+// hasConflicts checks, if the index contains unmerged entries
+func (idx *gitIndex) hasConflicts() bool {
+	for _, entry := range idx.entries {
+		if entry.stage() != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// writeLockedFile writes a file of the .git directory like git does:
+// the content is written to "<path>.lock", which is renamed afterwards.
+// When the lock file already exists, another git process is working on the file.
+func writeLockedFile(path string, content []byte) error {
+	lockPath := path + ".lock"
+	lock, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%s exists, another git process seems to be running", lockPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = lock.Write(content)
+	closeErr := lock.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(lockPath)
+		return err
+	}
+
+	err = os.Rename(lockPath, path)
+	if err != nil {
+		os.Remove(lockPath)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,418 @@
+package repository
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// memoryCommit is a commit of the in-memory repository
+type memoryCommit struct {
+	hash    string
+	parent  string
+	message string
+	paths   []string
+	files   map[string]string
+}
+
+// Memory is an in-memory repository without a work tree.
+// It is meant for driving tagger in tests and tools without a real checkout:
+// changes are simulated with Change, which CommitAll turns into a commit.
+type Memory struct {
+	commits map[string]memoryCommit
+	head    string
+	tags    map[string]string
+	remotes map[string]map[string]string
+	changed []string
+	work    map[string]string
+
+	branch   string
+	upstream string
+	behind   int
+	pushed   string
+}
+
+// NewMemory creates an in-memory repository with an initial commit
+func NewMemory() *Memory {
+	m := &Memory{
+		commits: make(map[string]memoryCommit),
+		tags:    make(map[string]string),
+		remotes: make(map[string]map[string]string),
+		work:    make(map[string]string),
+		branch:  "main",
+	}
+	m.Commit("initial commit")
+	return m
+}
+
+// Commit creates a commit touching the given paths (relative to the repository root) and returns its hash
+func (m *Memory) Commit(message string, paths ...string) string {
+	content := fmt.Sprintf("%s\n%s\n%d\n%s", m.head, message, len(m.commits), strings.Join(paths, "\n"))
+	sum := sha1.Sum([]byte(content))
+	hash := hex.EncodeToString(sum[:])
+
+	m.commits[hash] = memoryCommit{
+		hash:    hash,
+		parent:  m.head,
+		message: message,
+		paths:   slices.Clone(paths),
+		files:   maps.Clone(m.work),
+	}
+	m.head = hash
+	return hash
+}
+
+// SetRemoteTag sets a tag of the remote to the commit hash, creating the remote if needed
+func (m *Memory) SetRemoteTag(remote string, name string, hash string) {
+	if m.remotes[remote] == nil {
+		m.remotes[remote] = make(map[string]string)
+	}
+	m.remotes[remote][name] = hash
+}
+
+// SetBranch sets the name of the checked out branch, an empty name detaches HEAD
+func (m *Memory) SetBranch(name string) {
+	m.branch = name
+}
+
+// SetUpstream sets the upstream of the checked out branch and the number of commits HEAD is behind it
+func (m *Memory) SetUpstream(upstream string, behind int) {
+	m.upstream = upstream
+	m.behind = behind
+}
+
+// Change marks the paths as modified in the work tree
+func (m *Memory) Change(paths ...string) {
+	for _, path := range paths {
+		if !slices.Contains(m.changed, path) {
+			m.changed = append(m.changed, path)
+		}
+	}
+}
+
+// WriteFile sets the content of a file in the work tree and marks it as modified
+func (m *Memory) WriteFile(path string, content string) {
+	m.work[path] = content
+	m.Change(path)
+}
+
+// Message returns the message of the commit of the revision
+func (m *Memory) Message(revision string) (string, error) {
+	hash, err := m.Resolve(revision)
+	if err != nil {
+		return "", err
+	}
+	return m.commits[hash].message, nil
+}
+
+func (m *Memory) Tags() ([]string, error) {
+	result := make([]string, 0, len(m.tags))
+	for name := range m.tags {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (m *Memory) TagsAt(revision string) ([]string, error) {
+	hash, err := m.Resolve(revision)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0)
+	for name, target := range m.tags {
+		if target == hash {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (m *Memory) MergedTags(revision string) ([]string, error) {
+	hash, err := m.Resolve(revision)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]bool)
+	for ; hash != ""; hash = m.commits[hash].parent {
+		merged[hash] = true
+	}
+
+	result := make([]string, 0)
+	for name, target := range m.tags {
+		if merged[target] {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (m *Memory) TagCommits() (map[string]string, error) {
+	return maps.Clone(m.tags), nil
+}
+
+func (m *Memory) RemoteTags(remote string) (map[string]string, error) {
+	tags, ok := m.remotes[remote]
+	if !ok {
+		return nil, fmt.Errorf("unknown remote %s", remote)
+	}
+	return maps.Clone(tags), nil
+}
+
+func (m *Memory) Resolve(revision string) (string, error) {
+	base, generations, err := splitRevision(revision)
+	if err != nil {
+		return "", err
+	}
+
+	hash := ""
+	switch {
+	case base == "HEAD":
+		hash = m.head
+	case m.tags[base] != "":
+		hash = m.tags[base]
+	default:
+		for candidate := range m.commits {
+			if len(base) >= 4 && strings.HasPrefix(candidate, base) {
+				if hash != "" {
+					return "", fmt.Errorf("ambiguous revision %s", revision)
+				}
+				hash = candidate
+			}
+		}
+	}
+	if hash == "" {
+		return "", fmt.Errorf("unknown revision %s", revision)
+	}
+
+	for i := 0; i < generations; i++ {
+		hash = m.commits[hash].parent
+		if hash == "" {
+			return "", fmt.Errorf("unknown revision %s", revision)
+		}
+	}
+	return hash, nil
+}
+
+func (m *Memory) Branch() (string, error) {
+	return m.branch, nil
+}
+
+func (m *Memory) Behind() (string, int, error) {
+	if m.branch == "" {
+		return "", 0, nil
+	}
+	return m.upstream, m.behind, nil
+}
+
+func (m *Memory) CreateTag(name string, revision string, message string) error {
+	if _, exists := m.tags[name]; exists {
+		return fmt.Errorf("tag %s already exists", name)
+	}
+	hash, err := m.Resolve(revision)
+	if err != nil {
+		return err
+	}
+	m.tags[name] = hash
+	return nil
+}
+
+func (m *Memory) MoveTag(name string, revision string, message string) error {
+	hash, err := m.Resolve(revision)
+	if err != nil {
+		return err
+	}
+	m.tags[name] = hash
+	return nil
+}
+
+func (m *Memory) DeleteTag(name string) error {
+	if _, exists := m.tags[name]; !exists {
+		return fmt.Errorf("tag %s not found", name)
+	}
+	delete(m.tags, name)
+	return nil
+}
+
+func (m *Memory) CommitAll(message string) error {
+	m.Commit(message, m.changed...)
+	m.changed = nil
+	return nil
+}
+
+func (m *Memory) HasUncommittedChanges() (bool, error) {
+	return len(m.changed) > 0, nil
+}
+
+func (m *Memory) CountCommits(since string, revision string, path string) (int, error) {
+	head, err := m.Resolve(revision)
+	if err != nil {
+		return 0, err
+	}
+
+	// The history is linear, so the commits reachable from since are its ancestors
+	excluded := make(map[string]bool)
+	if since != "" {
+		stop, err := m.Resolve(since)
+		if err != nil {
+			return 0, err
+		}
+		for ; stop != ""; stop = m.commits[stop].parent {
+			excluded[stop] = true
+		}
+	}
+
+	count := 0
+	for hash := head; hash != "" && !excluded[hash]; hash = m.commits[hash].parent {
+		if m.touches(m.commits[hash], path) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *Memory) ResetHard(revision string) error {
+	hash, err := m.Resolve(revision)
+	if err != nil {
+		return err
+	}
+	m.head = hash
+	m.changed = nil
+	m.work = maps.Clone(m.commits[hash].files)
+	return nil
+}
+
+func (m *Memory) Messages(since string, revision string) ([]string, error) {
+	hash, err := m.Resolve(revision)
+	if err != nil {
+		return nil, err
+	}
+	stop := ""
+	if since != "" {
+		stop, err = m.Resolve(since)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]string, 0)
+	for ; hash != "" && hash != stop; hash = m.commits[hash].parent {
+		result = append(result, m.commits[hash].message)
+	}
+	return result, nil
+}
+
+func (m *Memory) Commits(revision string, path string) ([]string, error) {
+	hash, err := m.Resolve(revision)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0)
+	for ; hash != ""; hash = m.commits[hash].parent {
+		if m.touches(m.commits[hash], path) {
+			result = append(result, hash)
+		}
+	}
+	return result, nil
+}
+
+// touches checks, if the commit changed the path (or a file below it)
+func (m *Memory) touches(commit memoryCommit, path string) bool {
+	return path == "" || path == "." || slices.ContainsFunc(commit.paths, func(changed string) bool {
+		return changed == path || strings.HasPrefix(changed, strings.TrimSuffix(path, "/")+"/")
+	})
+}
+
+// Files returns the files of the commit of the revision, written with WriteFile
+func (m *Memory) Files(revision string) (fs.FS, error) {
+	hash, err := m.Resolve(revision)
+	if err != nil {
+		return nil, err
+	}
+
+	files := m.commits[hash].files
+	keys := make(map[string]string, len(files))
+	for path := range files {
+		keys[path] = path
+	}
+	return &treeFS{files: keys, read: func(path string) ([]byte, error) {
+		return []byte(files[path]), nil
+	}}, nil
+}
+
+// Pushed checks, if the commit is contained in the pushed commit, see SetPushed
+func (m *Memory) Pushed(hash string) (bool, error) {
+	for current := m.pushed; current != ""; current = m.commits[current].parent {
+		if current == hash {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SetPushed marks the commit of the revision and its ancestors as pushed
+func (m *Memory) SetPushed(revision string) error {
+	hash, err := m.Resolve(revision)
+	if err != nil {
+		return err
+	}
+	m.pushed = hash
+	return nil
+}
+
+func (m *Memory) DeleteRemoteTag(remote string, name string) error {
+	if _, ok := m.remotes[remote][name]; !ok {
+		return fmt.Errorf("tag %s not found on %s", name, remote)
+	}
+	delete(m.remotes[remote], name)
+	return nil
+}
+
+func (m *Memory) GitDir() string {
+	return ""
+}
+
+// splitRevision splits the ~n and ^ suffixes of a revision, returning the base and the number of parent steps.
+// Only first parents exist in the in-memory repository, so ^n with n > 1 is rejected.
+func splitRevision(revision string) (string, int, error) {
+	index := strings.IndexAny(revision, "~^")
+	if index < 0 {
+		return revision, 0, nil
+	}
+
+	base, suffix := revision[:index], revision[index:]
+	generations := 0
+	for suffix != "" {
+		operator := suffix[0]
+		suffix = suffix[1:]
+		digits := len(suffix) - len(strings.TrimLeft(suffix, "0123456789"))
+		number := 1
+		if digits > 0 {
+			number, _ = strconv.Atoi(suffix[:digits])
+			suffix = suffix[digits:]
+		}
+		if operator == '^' && number > 1 {
+			return "", 0, fmt.Errorf("unknown revision %s", revision)
+		}
+		if operator == '^' && number == 0 {
+			continue
+		}
+		if operator == '~' {
+			generations += number
+		} else {
+			generations++
+		}
+	}
+	return base, generations, nil
+}
@@ -0,0 +1,67 @@
+package repository_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/MatthiasSchild/tagger/repository"
+)
+
+func TestMemory(t *testing.T) {
+	var repo repository.Repository
+	memory := repository.NewMemory()
+	repo = memory
+
+	err := repo.CreateTag("v1.0.0", "HEAD", "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.CreateTag("v1.0.0", "HEAD", "v1.0.0") == nil {
+		t.Error("creating an existing tag should fail")
+	}
+
+	memory.Commit("api", "api/main.go")
+	memory.Change("package.json")
+	dirty, _ := repo.HasUncommittedChanges()
+	if !dirty {
+		t.Error("changes should be uncommitted")
+	}
+	err = repo.CommitAll("v1.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dirty, _ = repo.HasUncommittedChanges()
+	if dirty {
+		t.Error("changes should be committed")
+	}
+
+	count, _ := repo.CountCommits("v1.0.0", "HEAD", "")
+	if count != 2 {
+		t.Errorf("expected 2 commits since v1.0.0, got %d", count)
+	}
+	count, _ = repo.CountCommits("v1.0.0", "HEAD", "api")
+	if count != 1 {
+		t.Errorf("expected 1 commit touching api since v1.0.0, got %d", count)
+	}
+
+	tagged, _ := repo.Resolve("v1.0.0")
+	parent, _ := repo.Resolve("HEAD~2")
+	if tagged != parent {
+		t.Errorf("HEAD~2 should be the tagged commit, got %s instead of %s", parent, tagged)
+	}
+	message, _ := memory.Message("HEAD")
+	if message != "v1.0.1" {
+		t.Errorf("unexpected message of HEAD: %s", message)
+	}
+
+	_ = repo.CreateTag("v1.0.1", "HEAD", "v1.0.1")
+	tags, _ := repo.TagsAt("HEAD")
+	if !slices.Equal(tags, []string{"v1.0.1"}) {
+		t.Errorf("unexpected tags at HEAD: %v", tags)
+	}
+	_ = repo.DeleteTag("v1.0.1")
+	tags, _ = repo.Tags()
+	if !slices.Equal(tags, []string{"v1.0.0"}) {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+}
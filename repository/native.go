@@ -0,0 +1,1367 @@
+package repository
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var hashRegex = regexp.MustCompile(`^[0-9a-f]{40}$`)
+var abbreviatedHashRegex = regexp.MustCompile(`^[0-9a-f]{4,39}$`)
+var revisionSuffixRegex = regexp.MustCompile(`^(.*?)((?:[~^][0-9]*)*)$`)
+var revisionOperatorRegex = regexp.MustCompile(`[~^][0-9]*`)
+
+// nativeRepository reads and writes the .git directory without running the git binary.
+// Hooks are not run and only SHA-1 repositories are supported.
+// Written index files lose their optional extensions (e.g. the cached trees) and the device, inode
+// and owner of the staged files, git rebuilds them when it needs them.
+type nativeRepository struct {
+	gitDir    string
+	commonDir string
+	workTree  string
+	objects   *objectStore
+}
+
+// packedRef is a reference of the packed-refs file
+type packedRef struct {
+	hash   string
+	peeled string
+}
+
+// OpenNative opens the repository containing the directory with the native backend.
+// Linked work trees (.git files) are supported.
+func OpenNative(dir string) (Repository, error) {
+	current, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		dotGit := filepath.Join(current, ".git")
+		info, err := os.Stat(dotGit)
+		if err == nil {
+			return openNativeAt(current, dotGit, info)
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return nil, fmt.Errorf("not a git repository: %s", dir)
+		}
+		current = parent
+	}
+}
+
+func openNativeAt(workTree string, dotGit string, info os.FileInfo) (Repository, error) {
+	gitDir := dotGit
+	if !info.IsDir() {
+		content, err := os.ReadFile(dotGit)
+		if err != nil {
+			return nil, err
+		}
+		path, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+		if !ok {
+			return nil, fmt.Errorf("invalid .git file in %s", workTree)
+		}
+		gitDir = strings.TrimSpace(path)
+		if !filepath.IsAbs(gitDir) {
+			gitDir = filepath.Join(workTree, gitDir)
+		}
+	}
+
+	commonDir := gitDir
+	if content, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(content))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+
+	gitConfig := make(map[string]string)
+	if content, err := os.ReadFile(filepath.Join(commonDir, "config")); err == nil {
+		parseGitConfig(string(content), gitConfig)
+	}
+	if format := gitConfig["extensions.objectformat"]; format != "" && format != "sha1" {
+		return nil, fmt.Errorf("object format %s is not supported by the native backend", format)
+	}
+
+	return &nativeRepository{
+		gitDir:    gitDir,
+		commonDir: commonDir,
+		workTree:  workTree,
+		objects:   newObjectStore(filepath.Join(commonDir, "objects")),
+	}, nil
+}
+
+// refPath returns the path of a loose reference, HEAD belongs to the (linked) work tree
+func (r *nativeRepository) refPath(name string) string {
+	if name == "HEAD" {
+		return filepath.Join(r.gitDir, name)
+	}
+	return filepath.Join(r.commonDir, filepath.FromSlash(name))
+}
+
+func (r *nativeRepository) packedRefs() (map[string]packedRef, error) {
+	result := make(map[string]packedRef)
+
+	file, err := os.Open(filepath.Join(r.commonDir, "packed-refs"))
+	if errors.Is(err, os.ErrNotExist) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	last := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "^") {
+			if ref, ok := result[last]; ok {
+				ref.peeled = line[1:]
+				result[last] = ref
+			}
+			continue
+		}
+
+		hash, name, ok := strings.Cut(line, " ")
+		if ok {
+			result[name] = packedRef{hash: hash}
+			last = name
+		}
+	}
+
+	return result, scanner.Err()
+}
+
+// readRef returns the hash of a reference, following symbolic references.
+// An empty hash is returned, when the reference does not exist.
+func (r *nativeRepository) readRef(name string) (string, error) {
+	return r.readRefWith(name, nil)
+}
+
+// readRefWith reads a reference like readRef, using the already read packed references.
+// When packed is nil, the packed-refs file is read if needed.
+func (r *nativeRepository) readRefWith(name string, packed map[string]packedRef) (string, error) {
+	for depth := 0; depth < 10; depth++ {
+		content, err := os.ReadFile(r.refPath(name))
+		if err == nil {
+			value := strings.TrimSpace(string(content))
+			if target, ok := strings.CutPrefix(value, "ref:"); ok {
+				name = strings.TrimSpace(target)
+				continue
+			}
+			return value, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) && !errors.Is(err, fs.ErrInvalid) {
+			return "", err
+		}
+
+		if packed == nil {
+			packed, err = r.packedRefs()
+			if err != nil {
+				return "", err
+			}
+		}
+		return packed[name].hash, nil
+	}
+
+	return "", fmt.Errorf("too many symbolic references: %s", name)
+}
+
+// refNames returns the names of all references with the prefix (e.g. "refs/tags/")
+func (r *nativeRepository) refNames(prefix string) ([]string, error) {
+	found := make(map[string]bool)
+
+	packed, err := r.packedRefs()
+	if err != nil {
+		return nil, err
+	}
+	for name := range packed {
+		if strings.HasPrefix(name, prefix) {
+			found[name] = true
+		}
+	}
+
+	root := r.refPath(strings.TrimSuffix(prefix, "/"))
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), ".lock") {
+			return nil
+		}
+		relative, err := filepath.Rel(r.commonDir, path)
+		if err != nil {
+			return err
+		}
+		found[filepath.ToSlash(relative)] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(found))
+	for name := range found {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// writeRef points a reference at the hash, symbolic references are followed
+func (r *nativeRepository) writeRef(name string, hash string) error {
+	for depth := 0; depth < 10; depth++ {
+		content, err := os.ReadFile(r.refPath(name))
+		if err != nil {
+			break
+		}
+		target, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "ref:")
+		if !ok {
+			break
+		}
+		name = strings.TrimSpace(target)
+	}
+
+	path := r.refPath(name)
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	return writeLockedFile(path, []byte(hash+"\n"))
+}
+
+// peel follows annotated tags to the object they point at
+func (r *nativeRepository) peel(hash string) (string, string, error) {
+	for depth := 0; depth < 10; depth++ {
+		objType, content, err := r.objects.read(hash)
+		if err != nil {
+			return "", "", err
+		}
+		if objType != objectTag {
+			return objType, hash, nil
+		}
+		_, hash, err = parseTag(content)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	return "", "", fmt.Errorf("too many nested tags")
+}
+
+func (r *nativeRepository) readCommit(hash string) (commitObject, error) {
+	content, err := r.objects.readTyped(hash, objectCommit)
+	if err != nil {
+		return commitObject{}, err
+	}
+	return parseCommit(content)
+}
+
+func (r *nativeRepository) Tags() ([]string, error) {
+	names, err := r.refNames("refs/tags/")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		result = append(result, strings.TrimPrefix(name, "refs/tags/"))
+	}
+	return result, nil
+}
+
+func (r *nativeRepository) TagsAt(revision string) ([]string, error) {
+	commit, err := r.Resolve(revision)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := r.TagCommits()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0)
+	for name, target := range commits {
+		if target == commit {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (r *nativeRepository) TagCommits() (map[string]string, error) {
+	packed, err := r.packedRefs()
+	if err != nil {
+		return nil, err
+	}
+	names, err := r.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string, len(names))
+	for _, name := range names {
+		ref := "refs/tags/" + name
+		hash, err := r.readRefWith(ref, packed)
+		if err != nil {
+			return nil, err
+		}
+
+		target := hash
+		if packedRef, ok := packed[ref]; ok && packedRef.hash == hash && packedRef.peeled != "" {
+			target = packedRef.peeled
+		} else {
+			_, target, err = r.peel(hash)
+			if err != nil {
+				return nil, err
+			}
+		}
+		result[name] = target
+	}
+	return result, nil
+}
+
+func (r *nativeRepository) MergedTags(revision string) ([]string, error) {
+	start, err := r.Resolve(revision)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := r.TagCommits()
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]bool)
+	err = r.walkCommits([]string{start}, func(hash string, commit commitObject) ([]string, error) {
+		merged[hash] = true
+		return commit.parents, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0)
+	for name, commit := range commits {
+		if merged[commit] {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (r *nativeRepository) RemoteTags(remote string) (map[string]string, error) {
+	// The native backend does not implement the transfer protocols, so the git binary is used
+	return lsRemoteTags(r.workTree, remote)
+}
+
+func (r *nativeRepository) Resolve(revision string) (string, error) {
+	parts := revisionSuffixRegex.FindStringSubmatch(revision)
+	base, suffix := parts[1], parts[2]
+
+	hash, err := r.resolveName(base)
+	if err != nil {
+		return "", err
+	}
+	objType, hash, err := r.peel(hash)
+	if err != nil {
+		return "", err
+	}
+	if objType != objectCommit {
+		return "", fmt.Errorf("%s is not a commit", revision)
+	}
+
+	for _, operator := range revisionOperatorRegex.FindAllString(suffix, -1) {
+		count := 1
+		if len(operator) > 1 {
+			count, _ = strconv.Atoi(operator[1:])
+		}
+
+		if operator[0] == '^' {
+			if count == 0 {
+				continue
+			}
+			commit, err := r.readCommit(hash)
+			if err != nil {
+				return "", err
+			}
+			if len(commit.parents) < count {
+				return "", fmt.Errorf("revision %s does not exist", revision)
+			}
+			hash = commit.parents[count-1]
+			continue
+		}
+
+		for i := 0; i < count; i++ {
+			commit, err := r.readCommit(hash)
+			if err != nil {
+				return "", err
+			}
+			if len(commit.parents) == 0 {
+				return "", fmt.Errorf("revision %s does not exist", revision)
+			}
+			hash = commit.parents[0]
+		}
+	}
+
+	return hash, nil
+}
+
+// resolveName resolves a reference name or (abbreviated) hash, like git rev-parse does
+func (r *nativeRepository) resolveName(name string) (string, error) {
+	if name == "" {
+		name = "HEAD"
+	}
+
+	if hashRegex.MatchString(name) && r.objects.has(name) {
+		return name, nil
+	}
+
+	candidates := []string{name, "refs/" + name, "refs/tags/" + name, "refs/heads/" + name, "refs/remotes/" + name, "refs/remotes/" + name + "/HEAD"}
+	for _, candidate := range candidates {
+		if candidate != "HEAD" && !strings.HasPrefix(candidate, "refs/") {
+			continue
+		}
+		hash, err := r.readRef(candidate)
+		if err != nil {
+			return "", err
+		}
+		if hash != "" {
+			return hash, nil
+		}
+	}
+
+	if abbreviatedHashRegex.MatchString(name) {
+		hashes, err := r.objects.findPrefix(name)
+		if err != nil {
+			return "", err
+		}
+		if len(hashes) == 1 {
+			return hashes[0], nil
+		}
+		if len(hashes) > 1 {
+			return "", fmt.Errorf("abbreviated hash %s is ambiguous", name)
+		}
+	}
+
+	return "", fmt.Errorf("unknown revision %s", name)
+}
+
+func (r *nativeRepository) Branch() (string, error) {
+	content, err := os.ReadFile(r.refPath("HEAD"))
+	if err != nil {
+		return "", err
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "ref:")
+	if !ok {
+		return "", nil
+	}
+	return strings.TrimPrefix(strings.TrimSpace(target), "refs/heads/"), nil
+}
+
+func (r *nativeRepository) Behind() (string, int, error) {
+	branch, err := r.Branch()
+	if err != nil || branch == "" {
+		return "", 0, err
+	}
+
+	gitConfig := r.gitConfig()
+	remote := gitConfig["branch."+branch+".remote"]
+	merge := strings.TrimPrefix(gitConfig["branch."+branch+".merge"], "refs/heads/")
+	if remote == "" || merge == "" {
+		return "", 0, nil
+	}
+
+	// The upstream is a remote tracking branch or a local branch for the remote "."
+	upstream, ref := remote+"/"+merge, "refs/remotes/"+remote+"/"+merge
+	if remote == "." {
+		upstream, ref = merge, "refs/heads/"+merge
+	}
+	upstreamHash, err := r.readRef(ref)
+	if err != nil {
+		return "", 0, err
+	}
+	if upstreamHash == "" {
+		return "", 0, fmt.Errorf("upstream %s of branch %s does not exist, fetch it first", upstream, branch)
+	}
+	head, err := r.Resolve("HEAD")
+	if err != nil {
+		return "", 0, err
+	}
+
+	contained := make(map[string]bool)
+	err = r.walkCommits([]string{head}, func(hash string, commit commitObject) ([]string, error) {
+		contained[hash] = true
+		return commit.parents, nil
+	})
+	if err != nil {
+		return "", 0, err
+	}
+
+	count := 0
+	err = r.walkCommits([]string{upstreamHash}, func(hash string, commit commitObject) ([]string, error) {
+		if contained[hash] {
+			return nil, nil
+		}
+		count++
+		return commit.parents, nil
+	})
+	return upstream, count, err
+}
+
+func (r *nativeRepository) CreateTag(name string, revision string, message string) error {
+	ref := "refs/tags/" + name
+	existing, err := r.readRef(ref)
+	if err != nil {
+		return err
+	}
+	if existing != "" {
+		return fmt.Errorf("tag %s already exists", name)
+	}
+
+	return r.writeTag(name, revision, message)
+}
+
+func (r *nativeRepository) MoveTag(name string, revision string, message string) error {
+	return r.writeTag(name, revision, message)
+}
+
+// writeTag points the tag at the commit of the revision, replacing an existing one.
+// With a message, the reference points to a new tag object, otherwise directly to the commit.
+func (r *nativeRepository) writeTag(name string, revision string, message string) error {
+	commit, err := r.Resolve(revision)
+	if err != nil {
+		return err
+	}
+	if message == "" {
+		return r.writeRef("refs/tags/"+name, commit)
+	}
+
+	tagger, err := signature(r.gitConfig(), "COMMITTER")
+	if err != nil {
+		return err
+	}
+	content := fmt.Sprintf("object %s\ntype commit\ntag %s\ntagger %s\n\n%s\n", commit, name, tagger, message)
+	hash, err := r.objects.write(objectTag, []byte(content))
+	if err != nil {
+		return err
+	}
+	return r.writeRef("refs/tags/"+name, hash)
+}
+
+func (r *nativeRepository) DeleteTag(name string) error {
+	ref := "refs/tags/" + name
+	found := false
+
+	err := os.Remove(r.refPath(ref))
+	if err == nil {
+		found = true
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	packedPath := filepath.Join(r.commonDir, "packed-refs")
+	content, err := os.ReadFile(packedPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	// The peeled line (^hash) following the reference is removed too
+	lines := make([]string, 0)
+	removing := false
+	for _, line := range strings.Split(string(content), "\n") {
+		if removing && strings.HasPrefix(line, "^") {
+			continue
+		}
+		removing = strings.HasSuffix(line, " "+ref)
+		if removing {
+			found = true
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if len(content) > 0 {
+		err = writeLockedFile(packedPath, []byte(strings.Join(lines, "\n")))
+		if err != nil {
+			return err
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("tag %s not found", name)
+	}
+	return nil
+}
+
+func (r *nativeRepository) gitConfig() map[string]string {
+	return readGitConfig(filepath.Join(r.commonDir, "config"))
+}
+
+func (r *nativeRepository) indexPath() string {
+	return filepath.Join(r.gitDir, "index")
+}
+
+// headTree returns the tree hash of HEAD or an empty string for a repository without commits
+func (r *nativeRepository) headTree() (string, string, error) {
+	head, err := r.readRef("HEAD")
+	if err != nil || head == "" {
+		return "", "", err
+	}
+	commit, err := r.readCommit(head)
+	if err != nil {
+		return "", "", err
+	}
+	return head, commit.tree, nil
+}
+
+// fileMode returns the mode of a file in the work tree as it is written into trees
+func fileMode(info os.FileInfo) string {
+	if info.Mode()&os.ModeSymlink != 0 {
+		return modeSymlink
+	}
+	if info.Mode()&0111 != 0 {
+		return modeExecutable
+	}
+	return modeFile
+}
+
+// blobOfFile returns the mode and the blob content of a file in the work tree
+func blobOfFile(path string, info os.FileInfo) (string, []byte, error) {
+	mode := fileMode(info)
+	if mode == modeSymlink {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", nil, err
+		}
+		return mode, []byte(target), nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+	return mode, content, nil
+}
+
+// fileChanged checks, if the file in the work tree differs from the index entry
+func (r *nativeRepository) fileChanged(entry indexEntry) (bool, error) {
+	path := filepath.Join(r.workTree, filepath.FromSlash(entry.path))
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if entry.statMatches(info) {
+		return false, nil
+	}
+
+	mode, content, err := blobOfFile(path, info)
+	if err != nil {
+		return false, err
+	}
+	return mode != entry.treeMode() || hashObject(objectBlob, content) != entry.hash, nil
+}
+
+func (r *nativeRepository) HasUncommittedChanges() (bool, error) {
+	index, err := readIndex(r.indexPath())
+	if err != nil {
+		return false, err
+	}
+	if index.hasConflicts() {
+		return true, nil
+	}
+
+	_, headTree, err := r.headTree()
+	if err != nil {
+		return false, err
+	}
+	indexTree, err := r.buildTree(index.entries, false)
+	if err != nil {
+		return false, err
+	}
+	if (headTree == "" && len(index.entries) > 0) || (headTree != "" && indexTree != headTree) {
+		return true, nil
+	}
+
+	for _, entry := range index.entries {
+		if entry.treeMode() == modeGitlink || entry.flags&indexFlagAssumeValid != 0 || entry.extendedFlags&indexExtendedSkipWorktree != 0 {
+			continue
+		}
+		changed, err := r.fileChanged(entry)
+		if err != nil {
+			return false, err
+		}
+		if changed {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// buildTree builds the tree objects of the index entries and returns the hash of the root tree.
+// When write is false, only the hash is computed.
+func (r *nativeRepository) buildTree(entries []indexEntry, write bool) (string, error) {
+	return r.buildSubtree(entries, "", write)
+}
+
+func (r *nativeRepository) buildSubtree(entries []indexEntry, prefix string, write bool) (string, error) {
+	treeEntries := make([]treeEntry, 0)
+
+	for i := 0; i < len(entries); {
+		relative := strings.TrimPrefix(entries[i].path, prefix)
+		name, _, isDir := strings.Cut(relative, "/")
+		if !isDir {
+			treeEntries = append(treeEntries, treeEntry{mode: entries[i].treeMode(), name: name, hash: entries[i].hash})
+			i++
+			continue
+		}
+
+		dirPrefix := prefix + name + "/"
+		end := i
+		for end < len(entries) && strings.HasPrefix(entries[end].path, dirPrefix) {
+			end++
+		}
+		hash, err := r.buildSubtree(entries[i:end], dirPrefix, write)
+		if err != nil {
+			return "", err
+		}
+		treeEntries = append(treeEntries, treeEntry{mode: modeTree, name: name, hash: hash})
+		i = end
+	}
+
+	content, err := encodeTree(treeEntries)
+	if err != nil {
+		return "", err
+	}
+	if !write {
+		return hashObject(objectTree, content), nil
+	}
+	return r.objects.write(objectTree, content)
+}
+
+// stageAll updates the index like "git add --all" does
+func (r *nativeRepository) stageAll(index *gitIndex) error {
+	tracked := make(map[string]bool)
+	entries := make([]indexEntry, 0, len(index.entries))
+
+	for _, entry := range index.entries {
+		tracked[entry.path] = true
+		// Files outside of a sparse checkout are missing on purpose, like files assumed to be unchanged
+		if entry.treeMode() == modeGitlink || entry.flags&indexFlagAssumeValid != 0 || entry.extendedFlags&indexExtendedSkipWorktree != 0 {
+			entries = append(entries, entry)
+			continue
+		}
+
+		path := filepath.Join(r.workTree, filepath.FromSlash(entry.path))
+		info, err := os.Lstat(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !entry.statMatches(info) {
+			err = r.stageFile(&entry, path, info)
+			if err != nil {
+				return err
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	// Later patterns win, so the files are loaded from the lowest to the highest precedence
+	ignore := &ignoreMatcher{}
+	if path := globalExcludesFile(r.gitConfig()); path != "" {
+		ignore.load(path, "")
+	}
+	ignore.load(filepath.Join(r.commonDir, "info", "exclude"), "")
+
+	err := filepath.WalkDir(r.workTree, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(r.workTree, path)
+		if err != nil {
+			return err
+		}
+		relative = filepath.ToSlash(relative)
+
+		if entry.IsDir() {
+			if relative == "." {
+				ignore.load(filepath.Join(path, ".gitignore"), "")
+				return nil
+			}
+			if entry.Name() == ".git" || ignore.ignored(relative, true) || tracked[relative] {
+				return filepath.SkipDir
+			}
+			// Nested repositories are not added
+			if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+				return filepath.SkipDir
+			}
+			ignore.load(filepath.Join(path, ".gitignore"), relative)
+			return nil
+		}
+
+		// The .git file of linked work trees is never added
+		if entry.Name() == ".git" || tracked[relative] || ignore.ignored(relative, false) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		newEntry := indexEntry{path: relative}
+		err = r.stageFile(&newEntry, path, info)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, newEntry)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	index.entries = entries
+	index.sort()
+	return nil
+}
+
+// stageFile writes the blob of the file and updates the index entry
+func (r *nativeRepository) stageFile(entry *indexEntry, path string, info os.FileInfo) error {
+	mode, content, err := blobOfFile(path, info)
+	if err != nil {
+		return err
+	}
+	hash, err := r.objects.write(objectBlob, content)
+	if err != nil {
+		return err
+	}
+
+	parsedMode, _ := strconv.ParseUint(mode, 8, 32)
+	entry.mode = uint32(parsedMode)
+	entry.hash = hash
+	entry.setStat(info)
+	return nil
+}
+
+func (r *nativeRepository) CommitAll(message string) error {
+	index, err := readIndex(r.indexPath())
+	if err != nil {
+		return err
+	}
+	if index.hasConflicts() {
+		return fmt.Errorf("cannot commit, because there are unmerged files")
+	}
+
+	err = r.stageAll(index)
+	if err != nil {
+		return err
+	}
+	err = index.write(r.indexPath())
+	if err != nil {
+		return err
+	}
+
+	tree, err := r.buildTree(index.entries, true)
+	if err != nil {
+		return err
+	}
+	head, headTree, err := r.headTree()
+	if err != nil {
+		return err
+	}
+	if tree == headTree {
+		return fmt.Errorf("nothing to commit")
+	}
+
+	gitConfig := r.gitConfig()
+	author, err := signature(gitConfig, "AUTHOR")
+	if err != nil {
+		return err
+	}
+	committer, err := signature(gitConfig, "COMMITTER")
+	if err != nil {
+		return err
+	}
+
+	content := &strings.Builder{}
+	fmt.Fprintf(content, "tree %s\n", tree)
+	if head != "" {
+		fmt.Fprintf(content, "parent %s\n", head)
+	}
+	fmt.Fprintf(content, "author %s\ncommitter %s\n\n%s\n", author, committer, strings.TrimRight(message, "\n"))
+
+	hash, err := r.objects.write(objectCommit, []byte(content.String()))
+	if err != nil {
+		return err
+	}
+	return r.writeRef("HEAD", hash)
+}
+
+// treeFiles returns all files of a tree by their slash separated path
+func (r *nativeRepository) treeFiles(tree string, prefix string, result map[string]treeEntry) error {
+	content, err := r.objects.readTyped(tree, objectTree)
+	if err != nil {
+		return err
+	}
+	entries, err := parseTree(content)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.mode == modeTree {
+			err = r.treeFiles(entry.hash, prefix+entry.name+"/", result)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		result[prefix+entry.name] = entry
+	}
+	return nil
+}
+
+// treeEntryAt returns the hash of the entry at the slash separated path within a tree.
+// An empty hash is returned, when the path does not exist.
+func (r *nativeRepository) treeEntryAt(tree string, path string) (string, error) {
+	hash := tree
+	for _, name := range strings.Split(path, "/") {
+		objType, content, err := r.objects.read(hash)
+		if err != nil {
+			return "", err
+		}
+		if objType != objectTree {
+			return "", nil
+		}
+		entries, err := parseTree(content)
+		if err != nil {
+			return "", err
+		}
+
+		hash = ""
+		for _, entry := range entries {
+			if entry.name == name {
+				hash = entry.hash
+				break
+			}
+		}
+		if hash == "" {
+			return "", nil
+		}
+	}
+	return hash, nil
+}
+
+func (r *nativeRepository) ResetHard(revision string) error {
+	target, err := r.Resolve(revision)
+	if err != nil {
+		return err
+	}
+	head, err := r.Resolve("HEAD")
+	if err != nil {
+		return err
+	}
+	if target != head {
+		err = r.writeRef("HEAD", target)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, headTree, err := r.headTree()
+	if err != nil {
+		return err
+	}
+	files := make(map[string]treeEntry)
+	if headTree != "" {
+		err = r.treeFiles(headTree, "", files)
+		if err != nil {
+			return err
+		}
+	}
+
+	index, err := readIndex(r.indexPath())
+	if err != nil {
+		return err
+	}
+
+	// Files, which are tracked but not part of HEAD, are removed
+	for _, entry := range index.entries {
+		if _, ok := files[entry.path]; ok || entry.treeMode() == modeGitlink {
+			continue
+		}
+		path := filepath.Join(r.workTree, filepath.FromSlash(entry.path))
+		err = os.Remove(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		r.removeEmptyDirs(filepath.Dir(path))
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	entries := make([]indexEntry, 0, len(files))
+	for _, path := range paths {
+		file := files[path]
+		parsedMode, _ := strconv.ParseUint(file.mode, 8, 32)
+		entry := indexEntry{path: path, hash: file.hash, mode: uint32(parsedMode)}
+
+		if file.mode != modeGitlink {
+			fullPath := filepath.Join(r.workTree, filepath.FromSlash(path))
+			err = r.checkoutFile(file, fullPath)
+			if err != nil {
+				return err
+			}
+			info, err := os.Lstat(fullPath)
+			if err != nil {
+				return err
+			}
+			entry.setStat(info)
+		}
+		entries = append(entries, entry)
+	}
+
+	index.entries = entries
+	return index.write(r.indexPath())
+}
+
+// checkoutFile writes the blob of a tree entry into the work tree, when it differs
+func (r *nativeRepository) checkoutFile(file treeEntry, path string) error {
+	if info, err := os.Lstat(path); err == nil {
+		mode, content, err := blobOfFile(path, info)
+		if err == nil && mode == file.mode && hashObject(objectBlob, content) == file.hash {
+			return nil
+		}
+	}
+
+	content, err := r.objects.readTyped(file.hash, objectBlob)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	err = os.RemoveAll(path)
+	if err != nil {
+		return err
+	}
+
+	switch file.mode {
+	case modeSymlink:
+		return os.Symlink(string(content), path)
+	case modeExecutable:
+		return os.WriteFile(path, content, 0755)
+	default:
+		return os.WriteFile(path, content, 0644)
+	}
+}
+
+// removeEmptyDirs removes the directory and its parents, as long as they are empty
+func (r *nativeRepository) removeEmptyDirs(dir string) {
+	for dir != r.workTree && strings.HasPrefix(dir, r.workTree) {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+func (r *nativeRepository) Pushed(hash string) (bool, error) {
+	names, err := r.refNames("refs/remotes/")
+	if err != nil {
+		return false, err
+	}
+
+	start := make([]string, 0, len(names))
+	for _, name := range names {
+		remoteHash, err := r.readRef(name)
+		if err != nil {
+			return false, err
+		}
+		if remoteHash != "" {
+			start = append(start, remoteHash)
+		}
+	}
+
+	found := false
+	err = r.walkCommits(start, func(visited string, commit commitObject) ([]string, error) {
+		if found || visited == hash {
+			found = true
+			return nil, nil
+		}
+		return commit.parents, nil
+	})
+	return found, err
+}
+
+func (r *nativeRepository) DeleteRemoteTag(remote string, name string) error {
+	// The native backend does not implement the transfer protocols, so the git binary is used
+	return pushDeleteTag(r.workTree, remote, name)
+}
+
+func (r *nativeRepository) Messages(since string, revision string) ([]string, error) {
+	start, err := r.Resolve(revision)
+	if err != nil {
+		return nil, err
+	}
+	excluded, err := r.reachable(since)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0)
+	err = r.walkCommits([]string{start}, func(hash string, commit commitObject) ([]string, error) {
+		if excluded[hash] {
+			return nil, nil
+		}
+		result = append(result, commit.message)
+		return commit.parents, nil
+	})
+	return result, err
+}
+
+func (r *nativeRepository) Commits(revision string, path string) ([]string, error) {
+	hash, err := r.Resolve(revision)
+	if err != nil {
+		return nil, err
+	}
+
+	relative := ""
+	if path != "" {
+		relative, err = r.relativePath(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]string, 0)
+	for hash != "" {
+		commit, err := r.readCommit(hash)
+		if err != nil {
+			return nil, err
+		}
+		parent := ""
+		if len(commit.parents) > 0 {
+			parent = commit.parents[0]
+		}
+
+		changed := true
+		if relative != "" {
+			entry, err := r.treeEntryAt(commit.tree, relative)
+			if err != nil {
+				return nil, err
+			}
+			parentEntry := ""
+			if parent != "" {
+				parentCommit, err := r.readCommit(parent)
+				if err != nil {
+					return nil, err
+				}
+				parentEntry, err = r.treeEntryAt(parentCommit.tree, relative)
+				if err != nil {
+					return nil, err
+				}
+			}
+			changed = entry != parentEntry
+		}
+		if changed {
+			result = append(result, hash)
+		}
+		hash = parent
+	}
+	return result, nil
+}
+
+func (r *nativeRepository) Files(revision string) (fs.FS, error) {
+	hash, err := r.Resolve(revision)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := r.readCommit(hash)
+	if err != nil {
+		return nil, err
+	}
+	relative, err := r.relativePath(".")
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]treeEntry)
+	err = r.treeFiles(commit.tree, "", entries)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := ""
+	if relative != "" {
+		prefix = relative + "/"
+	}
+	files := make(map[string]string)
+	for path, entry := range entries {
+		if rest, ok := strings.CutPrefix(path, prefix); ok && entry.mode != modeGitlink {
+			files[rest] = entry.hash
+		}
+	}
+
+	return &treeFS{files: files, read: func(hash string) ([]byte, error) {
+		return r.objects.readTyped(hash, objectBlob)
+	}}, nil
+}
+
+func (r *nativeRepository) GitDir() string {
+	return r.commonDir
+}
+
+// relativePath returns the slash separated path relative to the work tree, empty for the work tree itself.
+// The path is relative to the working directory.
+func (r *nativeRepository) relativePath(path string) (string, error) {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	relative, err := filepath.Rel(r.workTree, absolute)
+	if err != nil {
+		return "", err
+	}
+	relative = filepath.ToSlash(relative)
+	if relative == "." {
+		relative = ""
+	}
+	if relative == ".." || strings.HasPrefix(relative, "../") {
+		return "", fmt.Errorf("path %s is outside of the repository", path)
+	}
+	return relative, nil
+}
+
+func (r *nativeRepository) CountCommits(since string, revision string, path string) (int, error) {
+	head, err := r.Resolve(revision)
+	if err != nil {
+		return 0, err
+	}
+
+	relative := ""
+	if path != "" {
+		relative, err = r.relativePath(path)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	excluded, err := r.reachable(since)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = r.walkCommits([]string{head}, func(hash string, commit commitObject) ([]string, error) {
+		if excluded[hash] {
+			return nil, nil
+		}
+		if relative == "" {
+			count++
+			return commit.parents, nil
+		}
+
+		entry, err := r.treeEntryAt(commit.tree, relative)
+		if err != nil {
+			return nil, err
+		}
+		if len(commit.parents) == 0 {
+			if entry != "" {
+				count++
+			}
+			return nil, nil
+		}
+
+		// Like git's history simplification: when the path is unchanged compared to a parent,
+		// the commit is not counted and only this parent is followed
+		for _, parent := range commit.parents {
+			parentCommit, err := r.readCommit(parent)
+			if err != nil {
+				return nil, err
+			}
+			parentEntry, err := r.treeEntryAt(parentCommit.tree, relative)
+			if err != nil {
+				return nil, err
+			}
+			if parentEntry == entry {
+				return []string{parent}, nil
+			}
+		}
+
+		count++
+		return commit.parents, nil
+	})
+	return count, err
+}
+
+// reachable returns the commits reachable from the revision, none for an empty revision
+func (r *nativeRepository) reachable(revision string) (map[string]bool, error) {
+	result := make(map[string]bool)
+	if revision == "" {
+		return result, nil
+	}
+
+	start, err := r.Resolve(revision)
+	if err != nil {
+		return nil, err
+	}
+	err = r.walkCommits([]string{start}, func(hash string, commit commitObject) ([]string, error) {
+		result[hash] = true
+		return commit.parents, nil
+	})
+	return result, err
+}
+
+// walkCommits visits every commit reachable from the start commits once.
+// The visit function returns the parents to follow.
+func (r *nativeRepository) walkCommits(start []string, visit func(hash string, commit commitObject) ([]string, error)) error {
+	visited := make(map[string]bool)
+	stack := append([]string{}, start...)
+
+	for len(stack) > 0 {
+		hash := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[hash] {
+			continue
+		}
+		visited[hash] = true
+
+		commit, err := r.readCommit(hash)
+		if err != nil {
+			return err
+		}
+		next, err := visit(hash, commit)
+		if err != nil {
+			return err
+		}
+		stack = append(stack, next...)
+	}
+
+	return nil
+}
@@ -0,0 +1,487 @@
+package repository_test
+
+import (
+	"io/fs"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/MatthiasSchild/tagger/repository"
+)
+
+// setupRepository creates a repository with packed and loose objects, annotated and lightweight tags
+func setupRepository(t *testing.T) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(dir, ".gitconfig-test"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	write := func(path string, content string) {
+		fullPath := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q", "-b", "main")
+	run("config", "user.name", "Tester")
+	run("config", "user.email", "tester@example.com")
+
+	write("README.md", "readme\n")
+	write(".gitignore", "*.log\nbuild/\n")
+	run("add", "--all")
+	run("commit", "-q", "-m", "initial")
+	run("tag", "-a", "v1.0.0", "-m", "v1.0.0")
+
+	write("api/main.go", "package main\n")
+	run("add", "--all")
+	run("commit", "-q", "-m", "api")
+	run("tag", "api/v0.1.0")
+
+	// Pack everything so far, the following objects stay loose
+	run("gc", "-q")
+
+	write("README.md", "readme\nchanged\n")
+	run("commit", "-q", "-am", "readme")
+	run("tag", "-a", "v1.1.0", "-m", "v1.1.0")
+
+	return dir
+}
+
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git %s failed: %v", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// walkFiles returns the contents of all files of the file system by path
+func walkFiles(t *testing.T, fsys fs.FS) map[string]string {
+	result := make(map[string]string)
+	err := fs.WalkDir(fsys, ".", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := fs.ReadFile(fsys, path)
+		result[path] = string(content)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestNativeReadsLikeExec(t *testing.T) {
+	dir := setupRepository(t)
+
+	native, err := repository.OpenNative(filepath.Join(dir, "api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reference := repository.NewExec(dir)
+
+	nativeTags, err := native.Tags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	referenceTags, _ := reference.Tags()
+	if !slices.Equal(nativeTags, referenceTags) {
+		t.Errorf("tags mismatch, native=%v, exec=%v", nativeTags, referenceTags)
+	}
+
+	nativeCommits, err := native.TagCommits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	referenceCommits, _ := reference.TagCommits()
+	if !maps.Equal(nativeCommits, referenceCommits) {
+		t.Errorf("tag commits mismatch, native=%v, exec=%v", nativeCommits, referenceCommits)
+	}
+
+	// The repository itself serves as remote, so ls-remote must see the same tags
+	remoteCommits, err := native.RemoteTags(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(remoteCommits, referenceCommits) {
+		t.Errorf("remote tags mismatch, remote=%v, local=%v", remoteCommits, referenceCommits)
+	}
+
+	for _, revision := range []string{"HEAD", "HEAD~1", "HEAD^^", "v1.0.0", "api/v0.1.0", "main"} {
+		nativeHash, err := native.Resolve(revision)
+		if err != nil {
+			t.Errorf("failed to resolve %s: %v", revision, err)
+			continue
+		}
+		referenceHash, _ := reference.Resolve(revision)
+		if nativeHash != referenceHash {
+			t.Errorf("%s resolves to %s, expect %s", revision, nativeHash, referenceHash)
+		}
+
+		nativeTagsAt, _ := native.TagsAt(revision)
+		referenceTagsAt, _ := reference.TagsAt(revision)
+		if !slices.Equal(nativeTagsAt, referenceTagsAt) {
+			t.Errorf("tags at %s mismatch, native=%v, exec=%v", revision, nativeTagsAt, referenceTagsAt)
+		}
+
+		nativeMerged, _ := native.MergedTags(revision)
+		referenceMerged, _ := reference.MergedTags(revision)
+		if !slices.Equal(nativeMerged, referenceMerged) {
+			t.Errorf("merged tags of %s mismatch, native=%v, exec=%v", revision, nativeMerged, referenceMerged)
+		}
+	}
+
+	// The files are relative to the working directory, like the ones of the exec backend
+	t.Chdir(dir)
+	for _, revision := range []string{"HEAD", "v1.0.0"} {
+		nativeFiles, err := native.Files(revision)
+		if err != nil {
+			t.Fatal(err)
+		}
+		referenceFiles, _ := reference.Files(revision)
+		nativePaths, referencePaths := walkFiles(t, nativeFiles), walkFiles(t, referenceFiles)
+		if !maps.Equal(nativePaths, referencePaths) {
+			t.Errorf("files of %s mismatch, native=%v, exec=%v", revision, nativePaths, referencePaths)
+		}
+	}
+	files, err := native.Files("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fstest.TestFS(files, "README.md", "api/main.go"); err != nil {
+		t.Error(err)
+	}
+
+	nativeBranch, err := native.Branch()
+	if err != nil || nativeBranch != "main" {
+		t.Errorf("unexpected branch %s (%v)", nativeBranch, err)
+	}
+
+	abbreviated := gitOutput(t, dir, "rev-parse", "--short", "HEAD~1")
+	if hash, err := native.Resolve(abbreviated); err != nil || hash != gitOutput(t, dir, "rev-parse", "HEAD~1") {
+		t.Errorf("abbreviated hash %s resolves to %s (%v)", abbreviated, hash, err)
+	}
+
+	for _, since := range []string{"", "v1.0.0", "api/v0.1.0"} {
+		for _, path := range []string{"", filepath.Join(dir, "api"), filepath.Join(dir, "README.md")} {
+			nativeCount, err := native.CountCommits(since, "HEAD", path)
+			if err != nil {
+				t.Errorf("failed to count commits since %s in %s: %v", since, path, err)
+				continue
+			}
+			referenceCount, _ := reference.CountCommits(since, "HEAD", path)
+			if nativeCount != referenceCount {
+				t.Errorf("commits since %s in %s: native=%d, exec=%d", since, path, nativeCount, referenceCount)
+			}
+		}
+	}
+
+	for _, since := range []string{"", "v1.0.0"} {
+		nativeMessages, err := native.Messages(since, "HEAD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		referenceMessages, _ := reference.Messages(since, "HEAD")
+		slices.Sort(nativeMessages)
+		slices.Sort(referenceMessages)
+		if !slices.Equal(nativeMessages, referenceMessages) {
+			t.Errorf("messages since %s mismatch, native=%q, exec=%q", since, nativeMessages, referenceMessages)
+		}
+	}
+
+	for _, path := range []string{filepath.Join(dir, "api"), filepath.Join(dir, "README.md")} {
+		nativeCommits, err := native.Commits("HEAD", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		referenceCommits, _ := reference.Commits("HEAD", path)
+		if !slices.Equal(nativeCommits, referenceCommits) {
+			t.Errorf("commits changing %s mismatch, native=%v, exec=%v", path, nativeCommits, referenceCommits)
+		}
+	}
+}
+
+func TestNativeWritesForGit(t *testing.T) {
+	dir := setupRepository(t)
+
+	native, err := repository.OpenNative(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := native.HasUncommittedChanges()
+	if err != nil || changed {
+		t.Fatalf("clean repository has changes: %v, %v", changed, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("release\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "version.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "debug.log"), []byte("ignored\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "api", "main.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err = native.HasUncommittedChanges()
+	if err != nil || !changed {
+		t.Fatalf("changes not detected: %v, %v", changed, err)
+	}
+
+	if err := native.CommitAll("v1.2.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := native.CreateTag("v1.2.0", "HEAD", "v1.2.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	if status := gitOutput(t, dir, "status", "--porcelain"); status != "" {
+		t.Errorf("work tree not clean after commit:\n%s", status)
+	}
+	if files := gitOutput(t, dir, "ls-files"); files != ".gitignore\nREADME.md\nversion.go" {
+		t.Errorf("committed files mismatch:\n%s", files)
+	}
+	if message := gitOutput(t, dir, "log", "-1", "--format=%s %an"); message != "v1.2.0 Tester" {
+		t.Errorf("commit mismatch: %s", message)
+	}
+	if description := gitOutput(t, dir, "describe"); description != "v1.2.0" {
+		t.Errorf("describe mismatch: %s", description)
+	}
+
+	for _, revision := range []string{"HEAD~1", "HEAD"} {
+		if err := native.MoveTag("v1", revision, ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := native.MoveTag("v1.2", revision, "v1.2"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	head := gitOutput(t, dir, "rev-parse", "HEAD")
+	if kinds := gitOutput(t, dir, "cat-file", "-t", "v1") + " " + gitOutput(t, dir, "cat-file", "-t", "v1.2"); kinds != "commit tag" {
+		t.Errorf("aliases should be lightweight and annotated, got %s", kinds)
+	}
+	if commit := gitOutput(t, dir, "rev-parse", "v1.2^{commit}"); commit != head || gitOutput(t, dir, "rev-parse", "v1") != head {
+		t.Error("aliases not moved to HEAD")
+	}
+	gitOutput(t, dir, "fsck", "--strict")
+
+	if err := native.DeleteTag("v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if tags := gitOutput(t, dir, "tag", "-l"); tags != "api/v0.1.0\nv1\nv1.1.0\nv1.2\nv1.2.0" {
+		t.Errorf("tags after delete mismatch:\n%s", tags)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("broken\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := native.ResetHard("HEAD"); err != nil {
+		t.Fatal(err)
+	}
+	if status := gitOutput(t, dir, "status", "--porcelain"); status != "" {
+		t.Errorf("work tree not clean after reset:\n%s", status)
+	}
+
+	// Undo the release commit: the branch moves back and the deleted file is restored
+	if err := native.ResetHard("HEAD~1"); err != nil {
+		t.Fatal(err)
+	}
+	if message := gitOutput(t, dir, "log", "-1", "--format=%s"); message == "v1.2.0" {
+		t.Error("branch not reset to the parent commit")
+	}
+	if files := gitOutput(t, dir, "ls-files"); files != ".gitignore\nREADME.md\napi/main.go" {
+		t.Errorf("files after reset mismatch:\n%s", files)
+	}
+	if status := gitOutput(t, dir, "status", "--porcelain"); status != "" {
+		t.Errorf("work tree not clean after reset:\n%s", status)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "version.go")); err == nil {
+		t.Error("file of the dropped commit still exists")
+	}
+
+	head = gitOutput(t, dir, "rev-parse", "HEAD")
+	pushed, err := native.Pushed(head)
+	if err != nil || pushed {
+		t.Errorf("commit without remote branches reported as pushed: %v, %v", pushed, err)
+	}
+	gitOutput(t, dir, "update-ref", "refs/remotes/origin/main", head)
+	pushed, err = native.Pushed(gitOutput(t, dir, "rev-parse", "HEAD~1"))
+	if err != nil || !pushed {
+		t.Errorf("ancestor of a remote branch not reported as pushed: %v, %v", pushed, err)
+	}
+	if native.GitDir() != filepath.Join(dir, ".git") {
+		t.Errorf("unexpected git directory %s", native.GitDir())
+	}
+}
+
+func TestNativeBehindLikeExec(t *testing.T) {
+	origin := setupRepository(t)
+	clone := filepath.Join(t.TempDir(), "clone")
+	gitOutput(t, origin, "clone", "-q", origin, clone)
+
+	for _, message := range []string{"first", "second"} {
+		gitOutput(t, origin, "commit", "-q", "--allow-empty", "-m", message)
+	}
+	gitOutput(t, clone, "fetch", "-q")
+
+	native, err := repository.OpenNative(clone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reference := repository.NewExec(clone)
+
+	upstream, behind, err := native.Behind()
+	if err != nil {
+		t.Fatal(err)
+	}
+	referenceUpstream, referenceBehind, _ := reference.Behind()
+	if upstream != "origin/main" || upstream != referenceUpstream || behind != 2 || behind != referenceBehind {
+		t.Errorf("native=%s %d, exec=%s %d", upstream, behind, referenceUpstream, referenceBehind)
+	}
+
+	gitOutput(t, clone, "checkout", "-q", "--detach")
+	branch, _ := native.Branch()
+	referenceBranch, _ := reference.Branch()
+	upstream, _, _ = native.Behind()
+	if branch != "" || referenceBranch != "" || upstream != "" {
+		t.Errorf("detached HEAD: native branch %q with upstream %q, exec branch %q", branch, upstream, referenceBranch)
+	}
+}
+
+func TestNativeCommitsSparseCheckout(t *testing.T) {
+	dir := setupRepository(t)
+	gitOutput(t, dir, "sparse-checkout", "set", "--no-cone", "/api/")
+	if _, err := os.Stat(filepath.Join(dir, "README.md")); err == nil {
+		t.Fatal("README.md should not be checked out")
+	}
+
+	native, err := repository.OpenNative(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err := native.HasUncommittedChanges()
+	if err != nil || changed {
+		t.Fatalf("sparse checkout has changes: %v, %v", changed, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "api", "version.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := native.CommitAll("sparse"); err != nil {
+		t.Fatal(err)
+	}
+	if files := gitOutput(t, dir, "show", "--format=", "--name-status", "HEAD"); files != "A\tapi/version.go" {
+		t.Errorf("only the new file should be committed, got:\n%s", files)
+	}
+	if status := gitOutput(t, dir, "status", "--porcelain"); status != "" {
+		t.Errorf("work tree not clean after commit:\n%s", status)
+	}
+}
+
+func TestNativeCommitIgnoresExcludedFiles(t *testing.T) {
+	dir := setupRepository(t)
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, "git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, "git", "ignore"), []byte(".DS_Store\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".git", "info", "exclude"), []byte("*.tmp\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The excludes of the common directory apply to linked work trees as well
+	worktree := filepath.Join(t.TempDir(), "worktree")
+	gitOutput(t, dir, "worktree", "add", "-q", worktree)
+	for _, name := range []string{".DS_Store", "notes.tmp", "version.go"} {
+		if err := os.WriteFile(filepath.Join(worktree, name), []byte("content\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	native, err := repository.OpenNative(worktree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := native.CommitAll("version"); err != nil {
+		t.Fatal(err)
+	}
+	if files := gitOutput(t, worktree, "show", "--format=", "--name-only", "HEAD"); files != "version.go" {
+		t.Errorf("excluded files should not be committed, got:\n%s", files)
+	}
+
+	// core.excludesFile replaces the default global excludes file
+	excludes := filepath.Join(home, "excludes")
+	if err := os.WriteFile(excludes, []byte("*.local\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitOutput(t, dir, "config", "core.excludesFile", excludes)
+	if err := os.WriteFile(filepath.Join(worktree, "settings.local"), []byte("content\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := native.CommitAll("ds store"); err != nil {
+		t.Fatal(err)
+	}
+	if files := gitOutput(t, worktree, "show", "--format=", "--name-only", "HEAD"); files != ".DS_Store" {
+		t.Errorf("only .DS_Store should be committed, got:\n%s", files)
+	}
+}
+
+func TestNativeCommitsModeChange(t *testing.T) {
+	dir := setupRepository(t)
+	// Refresh the stat data of the index, so only the mode differs afterwards
+	gitOutput(t, dir, "status", "--porcelain")
+
+	native, err := repository.OpenNative(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// chmod keeps the modification time and the size of the file
+	if err := os.Chmod(filepath.Join(dir, "README.md"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := native.HasUncommittedChanges()
+	if err != nil || !changed {
+		t.Fatalf("mode change not detected: %v, %v", changed, err)
+	}
+
+	if err := native.CommitAll("executable"); err != nil {
+		t.Fatal(err)
+	}
+	if status := gitOutput(t, dir, "status", "--porcelain"); status != "" {
+		t.Errorf("work tree not clean after commit:\n%s", status)
+	}
+	if mode := gitOutput(t, dir, "ls-tree", "HEAD", "README.md"); !strings.HasPrefix(mode, "100755 ") {
+		t.Errorf("mode change not committed: %s", mode)
+	}
+}
@@ -0,0 +1,585 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Types of the git objects
+const (
+	objectCommit = "commit"
+	objectTree   = "tree"
+	objectBlob   = "blob"
+	objectTag    = "tag"
+)
+
+// packObjectTypes maps the type numbers of pack entries to the object types
+var packObjectTypes = map[byte]string{
+	1: objectCommit,
+	2: objectTree,
+	3: objectBlob,
+	4: objectTag,
+}
+
+const (
+	packOfsDelta = 6
+	packRefDelta = 7
+)
+
+// errObjectNotFound is returned, when an object is neither loose nor in a pack
+var errObjectNotFound = errors.New("object not found")
+
+// objectStore reads loose and packed objects and writes loose objects
+type objectStore struct {
+	dirs        []string
+	packs       []*packFile
+	packsLoaded bool
+}
+
+func newObjectStore(objectsDir string) *objectStore {
+	store := &objectStore{dirs: []string{objectsDir}}
+
+	// Alternates are further object directories, e.g. of a shared clone
+	content, err := os.ReadFile(filepath.Join(objectsDir, "info", "alternates"))
+	if err == nil {
+		for _, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if !filepath.IsAbs(line) {
+				line = filepath.Join(objectsDir, line)
+			}
+			store.dirs = append(store.dirs, line)
+		}
+	}
+
+	return store
+}
+
+func (s *objectStore) loadPacks() error {
+	if s.packsLoaded {
+		return nil
+	}
+
+	for _, dir := range s.dirs {
+		indexPaths, err := filepath.Glob(filepath.Join(dir, "pack", "*.idx"))
+		if err != nil {
+			return err
+		}
+		for _, indexPath := range indexPaths {
+			pack, err := openPackFile(indexPath)
+			if err != nil {
+				return fmt.Errorf("failed to open %s: %v", indexPath, err)
+			}
+			s.packs = append(s.packs, pack)
+		}
+	}
+
+	s.packsLoaded = true
+	return nil
+}
+
+// read returns the type and the content of an object
+func (s *objectStore) read(hash string) (string, []byte, error) {
+	for _, dir := range s.dirs {
+		objType, content, err := readLooseObject(filepath.Join(dir, hash[:2], hash[2:]))
+		if err == nil {
+			return objType, content, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", nil, err
+		}
+	}
+
+	err := s.loadPacks()
+	if err != nil {
+		return "", nil, err
+	}
+
+	rawHash, err := hex.DecodeString(hash)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid object hash %s", hash)
+	}
+	for _, pack := range s.packs {
+		offset, ok := pack.find(rawHash)
+		if ok {
+			return pack.readAt(offset, s)
+		}
+	}
+
+	return "", nil, fmt.Errorf("%w: %s", errObjectNotFound, hash)
+}
+
+// readTyped reads an object and checks its type
+func (s *objectStore) readTyped(hash string, expected string) ([]byte, error) {
+	objType, content, err := s.read(hash)
+	if err != nil {
+		return nil, err
+	}
+	if objType != expected {
+		return nil, fmt.Errorf("object %s is a %s, not a %s", hash, objType, expected)
+	}
+	return content, nil
+}
+
+// has checks, if an object exists
+func (s *objectStore) has(hash string) bool {
+	for _, dir := range s.dirs {
+		if _, err := os.Stat(filepath.Join(dir, hash[:2], hash[2:])); err == nil {
+			return true
+		}
+	}
+
+	if s.loadPacks() != nil {
+		return false
+	}
+	rawHash, err := hex.DecodeString(hash)
+	if err != nil {
+		return false
+	}
+	for _, pack := range s.packs {
+		if _, ok := pack.find(rawHash); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// findPrefix returns the hashes of all objects starting with the (abbreviated) prefix
+func (s *objectStore) findPrefix(prefix string) ([]string, error) {
+	if len(prefix) < 4 {
+		return nil, fmt.Errorf("abbreviated hash %s is too short", prefix)
+	}
+
+	found := make(map[string]bool)
+	for _, dir := range s.dirs {
+		entries, err := os.ReadDir(filepath.Join(dir, prefix[:2]))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			hash := prefix[:2] + entry.Name()
+			if strings.HasPrefix(hash, prefix) {
+				found[hash] = true
+			}
+		}
+	}
+
+	err := s.loadPacks()
+	if err != nil {
+		return nil, err
+	}
+	for _, pack := range s.packs {
+		for _, hash := range pack.findPrefix(prefix) {
+			found[hash] = true
+		}
+	}
+
+	result := make([]string, 0, len(found))
+	for hash := range found {
+		result = append(result, hash)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// write stores an object as loose object and returns its hash.
+// Existing objects are not written again.
+func (s *objectStore) write(objType string, content []byte) (string, error) {
+	hash := hashObject(objType, content)
+	if s.has(hash) {
+		return hash, nil
+	}
+
+	dir := filepath.Join(s.dirs[0], hash[:2])
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	compressed := &bytes.Buffer{}
+	writer := zlib.NewWriter(compressed)
+	_, err = fmt.Fprintf(writer, "%s %d\x00", objType, len(content))
+	if err != nil {
+		return "", err
+	}
+	_, err = writer.Write(content)
+	if err != nil {
+		return "", err
+	}
+	err = writer.Close()
+	if err != nil {
+		return "", err
+	}
+
+	err = writeFileAtomic(filepath.Join(dir, hash[2:]), compressed.Bytes(), 0444)
+	if err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// hashObject computes the hash of an object, like "git hash-object" does
+func hashObject(objType string, content []byte) string {
+	hasher := sha1.New()
+	fmt.Fprintf(hasher, "%s %d\x00", objType, len(content))
+	hasher.Write(content)
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+func readLooseObject(path string) (string, []byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer file.Close()
+
+	reader, err := zlib.NewReader(bufio.NewReader(file))
+	if err != nil {
+		return "", nil, fmt.Errorf("corrupt object %s: %v", path, err)
+	}
+	defer reader.Close()
+
+	raw, err := io.ReadAll(reader)
+	if err != nil {
+		return "", nil, fmt.Errorf("corrupt object %s: %v", path, err)
+	}
+
+	header, content, ok := bytes.Cut(raw, []byte{0})
+	if !ok {
+		return "", nil, fmt.Errorf("corrupt object %s: missing header", path)
+	}
+	objType, rawSize, ok := strings.Cut(string(header), " ")
+	size, err := strconv.Atoi(rawSize)
+	if !ok || err != nil || size != len(content) {
+		return "", nil, fmt.Errorf("corrupt object %s: invalid header", path)
+	}
+	return objType, content, nil
+}
+
+// packFile reads objects of a pack using its index (version 2)
+type packFile struct {
+	file    *os.File
+	size    int64
+	hashes  []byte
+	offsets []int64
+	fanout  [256]uint32
+	cache   map[int64]packObject
+}
+
+type packObject struct {
+	objType string
+	content []byte
+}
+
+// maxPackCacheEntries limits the cached base objects of delta chains
+const maxPackCacheEntries = 256
+
+func openPackFile(indexPath string) (*packFile, error) {
+	index, err := os.ReadFile(indexPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(index) < 8+256*4 || !bytes.Equal(index[:4], []byte{0xff, 't', 'O', 'c'}) {
+		return nil, fmt.Errorf("unsupported pack index version")
+	}
+	if binary.BigEndian.Uint32(index[4:8]) != 2 {
+		return nil, fmt.Errorf("unsupported pack index version")
+	}
+
+	pack := &packFile{cache: make(map[int64]packObject)}
+	for i := range pack.fanout {
+		pack.fanout[i] = binary.BigEndian.Uint32(index[8+i*4:])
+	}
+
+	count := int(pack.fanout[255])
+	hashStart := 8 + 256*4
+	offsetStart := hashStart + count*20 + count*4
+	largeOffsetStart := offsetStart + count*4
+	if len(index) < largeOffsetStart {
+		return nil, fmt.Errorf("truncated pack index")
+	}
+
+	pack.hashes = index[hashStart : hashStart+count*20]
+	pack.offsets = make([]int64, count)
+	for i := 0; i < count; i++ {
+		offset := binary.BigEndian.Uint32(index[offsetStart+i*4:])
+		if offset&0x80000000 == 0 {
+			pack.offsets[i] = int64(offset)
+			continue
+		}
+		position := largeOffsetStart + int(offset&0x7fffffff)*8
+		if len(index) < position+8 {
+			return nil, fmt.Errorf("truncated pack index")
+		}
+		pack.offsets[i] = int64(binary.BigEndian.Uint64(index[position:]))
+	}
+
+	pack.file, err = os.Open(strings.TrimSuffix(indexPath, ".idx") + ".pack")
+	if err != nil {
+		return nil, err
+	}
+	info, err := pack.file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	pack.size = info.Size()
+
+	return pack, nil
+}
+
+// bounds returns the range of index entries, whose hash starts with the byte
+func (p *packFile) bounds(first byte) (int, int) {
+	start := 0
+	if first > 0 {
+		start = int(p.fanout[first-1])
+	}
+	return start, int(p.fanout[first])
+}
+
+func (p *packFile) hashAt(i int) []byte {
+	return p.hashes[i*20 : i*20+20]
+}
+
+func (p *packFile) find(hash []byte) (int64, bool) {
+	start, end := p.bounds(hash[0])
+	i := start + sort.Search(end-start, func(i int) bool {
+		return bytes.Compare(p.hashAt(start+i), hash) >= 0
+	})
+	if i < end && bytes.Equal(p.hashAt(i), hash) {
+		return p.offsets[i], true
+	}
+	return 0, false
+}
+
+func (p *packFile) findPrefix(prefix string) []string {
+	first, err := strconv.ParseUint(prefix[:2], 16, 8)
+	if err != nil {
+		return nil
+	}
+
+	result := make([]string, 0)
+	start, end := p.bounds(byte(first))
+	for i := start; i < end; i++ {
+		hash := hex.EncodeToString(p.hashAt(i))
+		if strings.HasPrefix(hash, prefix) {
+			result = append(result, hash)
+		}
+	}
+	return result
+}
+
+// readAt reads the object at the offset of the pack, resolving deltas
+func (p *packFile) readAt(offset int64, store *objectStore) (string, []byte, error) {
+	if cached, ok := p.cache[offset]; ok {
+		return cached.objType, cached.content, nil
+	}
+
+	header := make([]byte, 32)
+	n, err := p.file.ReadAt(header, offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", nil, err
+	}
+	header = header[:n]
+	if len(header) == 0 {
+		return "", nil, fmt.Errorf("invalid pack offset %d", offset)
+	}
+
+	position := 0
+	current := header[position]
+	position++
+	typeNumber := (current >> 4) & 0x07
+	size := int64(current & 0x0f)
+	shift := uint(4)
+	for current&0x80 != 0 {
+		if position >= len(header) {
+			return "", nil, fmt.Errorf("invalid pack entry at %d", offset)
+		}
+		current = header[position]
+		position++
+		size |= int64(current&0x7f) << shift
+		shift += 7
+	}
+
+	var baseType string
+	var baseContent []byte
+	switch typeNumber {
+	case packOfsDelta:
+		current = header[position]
+		position++
+		relative := int64(current & 0x7f)
+		for current&0x80 != 0 {
+			if position >= len(header) {
+				return "", nil, fmt.Errorf("invalid pack entry at %d", offset)
+			}
+			current = header[position]
+			position++
+			relative = ((relative + 1) << 7) | int64(current&0x7f)
+		}
+		baseType, baseContent, err = p.readAt(offset-relative, store)
+		if err != nil {
+			return "", nil, err
+		}
+	case packRefDelta:
+		if len(header) < position+20 {
+			return "", nil, fmt.Errorf("invalid pack entry at %d", offset)
+		}
+		baseType, baseContent, err = store.read(hex.EncodeToString(header[position : position+20]))
+		if err != nil {
+			return "", nil, err
+		}
+		position += 20
+	}
+
+	dataStart := offset + int64(position)
+	reader, err := zlib.NewReader(bufio.NewReader(io.NewSectionReader(p.file, dataStart, p.size-dataStart)))
+	if err != nil {
+		return "", nil, fmt.Errorf("corrupt pack entry at %d: %v", offset, err)
+	}
+	defer reader.Close()
+
+	data := make([]byte, size)
+	_, err = io.ReadFull(reader, data)
+	if err != nil {
+		return "", nil, fmt.Errorf("corrupt pack entry at %d: %v", offset, err)
+	}
+
+	objType := packObjectTypes[typeNumber]
+	if baseContent != nil {
+		objType = baseType
+		data, err = applyDelta(baseContent, data)
+		if err != nil {
+			return "", nil, fmt.Errorf("corrupt pack entry at %d: %v", offset, err)
+		}
+	}
+	if objType == "" {
+		return "", nil, fmt.Errorf("unknown pack entry type %d at %d", typeNumber, offset)
+	}
+
+	if len(p.cache) >= maxPackCacheEntries {
+		p.cache = make(map[int64]packObject)
+	}
+	p.cache[offset] = packObject{objType: objType, content: data}
+	return objType, data, nil
+}
+
+// applyDelta builds an object from its base and a delta of a pack
+func applyDelta(base []byte, delta []byte) ([]byte, error) {
+	position := 0
+	readSize := func() (int, error) {
+		size := 0
+		shift := uint(0)
+		for {
+			if position >= len(delta) {
+				return 0, fmt.Errorf("truncated delta")
+			}
+			current := delta[position]
+			position++
+			size |= int(current&0x7f) << shift
+			shift += 7
+			if current&0x80 == 0 {
+				return size, nil
+			}
+		}
+	}
+
+	baseSize, err := readSize()
+	if err != nil {
+		return nil, err
+	}
+	if baseSize != len(base) {
+		return nil, fmt.Errorf("delta base size mismatch")
+	}
+	resultSize, err := readSize()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]byte, 0, resultSize)
+	for position < len(delta) {
+		instruction := delta[position]
+		position++
+
+		if instruction&0x80 == 0 {
+			// Insert the following bytes
+			length := int(instruction)
+			if length == 0 || position+length > len(delta) {
+				return nil, fmt.Errorf("invalid delta instruction")
+			}
+			result = append(result, delta[position:position+length]...)
+			position += length
+			continue
+		}
+
+		// Copy a part of the base
+		copyOffset := 0
+		copySize := 0
+		for i := uint(0); i < 4; i++ {
+			if instruction&(1<<i) != 0 {
+				if position >= len(delta) {
+					return nil, fmt.Errorf("truncated delta")
+				}
+				copyOffset |= int(delta[position]) << (8 * i)
+				position++
+			}
+		}
+		for i := uint(0); i < 3; i++ {
+			if instruction&(1<<(4+i)) != 0 {
+				if position >= len(delta) {
+					return nil, fmt.Errorf("truncated delta")
+				}
+				copySize |= int(delta[position]) << (8 * i)
+				position++
+			}
+		}
+		if copySize == 0 {
+			copySize = 0x10000
+		}
+		if copyOffset+copySize > len(base) {
+			return nil, fmt.Errorf("invalid delta copy")
+		}
+		result = append(result, base[copyOffset:copyOffset+copySize]...)
+	}
+
+	if len(result) != resultSize {
+		return nil, fmt.Errorf("delta result size mismatch")
+	}
+	return result, nil
+}
+
+// writeFileAtomic writes the file through a temporary file, which is renamed afterwards
+func writeFileAtomic(path string, content []byte, perm os.FileMode) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), ".tagger-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	_, err = temp.Write(content)
+	if err != nil {
+		temp.Close()
+		return err
+	}
+	err = temp.Close()
+	if err != nil {
+		return err
+	}
+	err = os.Chmod(temp.Name(), perm)
+	if err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
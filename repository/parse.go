@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Modes of the tree entries
+const (
+	modeTree       = "40000"
+	modeFile       = "100644"
+	modeExecutable = "100755"
+	modeSymlink    = "120000"
+	modeGitlink    = "160000"
+)
+
+// commitObject contains the parts of a commit needed to walk the history
+type commitObject struct {
+	tree    string
+	parents []string
+	message string
+}
+
+// treeEntry is a single entry of a tree object
+type treeEntry struct {
+	mode string
+	name string
+	hash string
+}
+
+func parseCommit(content []byte) (commitObject, error) {
+	var result commitObject
+
+	header, message, _ := bytes.Cut(content, []byte("\n\n"))
+	result.message = string(message)
+	for _, line := range strings.Split(string(header), "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "tree":
+			result.tree = value
+		case "parent":
+			result.parents = append(result.parents, value)
+		}
+	}
+
+	if result.tree == "" {
+		return commitObject{}, fmt.Errorf("commit without tree")
+	}
+	return result, nil
+}
+
+// parseTag returns the type and hash of the object an annotated tag points at
+func parseTag(content []byte) (string, string, error) {
+	var objType, hash string
+
+	header, _, _ := bytes.Cut(content, []byte("\n\n"))
+	for _, line := range strings.Split(string(header), "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "object":
+			hash = value
+		case "type":
+			objType = value
+		}
+	}
+
+	if hash == "" || objType == "" {
+		return "", "", fmt.Errorf("tag without object")
+	}
+	return objType, hash, nil
+}
+
+func parseTree(content []byte) ([]treeEntry, error) {
+	result := make([]treeEntry, 0)
+
+	for len(content) > 0 {
+		header, rest, ok := bytes.Cut(content, []byte{0})
+		if !ok || len(rest) < 20 {
+			return nil, fmt.Errorf("corrupt tree")
+		}
+		mode, name, ok := strings.Cut(string(header), " ")
+		if !ok {
+			return nil, fmt.Errorf("corrupt tree")
+		}
+
+		result = append(result, treeEntry{
+			mode: mode,
+			name: name,
+			hash: hex.EncodeToString(rest[:20]),
+		})
+		content = rest[20:]
+	}
+
+	return result, nil
+}
+
+// encodeTree builds the content of a tree object.
+// The entries are sorted like git does: directories are compared with a trailing slash.
+func encodeTree(entries []treeEntry) ([]byte, error) {
+	sortKey := func(entry treeEntry) string {
+		if entry.mode == modeTree {
+			return entry.name + "/"
+		}
+		return entry.name
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return sortKey(entries[i]) < sortKey(entries[j])
+	})
+
+	result := &bytes.Buffer{}
+	for _, entry := range entries {
+		rawHash, err := hex.DecodeString(entry.hash)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(result, "%s %s\x00", entry.mode, entry.name)
+		result.Write(rawHash)
+	}
+	return result.Bytes(), nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+)
+
+// remoteTagsRepository combines the local tags with the tags of a remote
+type remoteTagsRepository struct {
+	Repository
+	remote string
+	warn   func(message string)
+	tags   map[string]string
+}
+
+// WithRemoteTags returns a repository, whose tags are the local tags combined with the tags of the remote.
+// This way versions can be computed in clones without tags (e.g. shallow clones of CI runners).
+// The remote is queried once, when the tags are needed for the first time.
+// Tags only existing locally and tags pointing at different commits are reported to warn.
+func WithRemoteTags(r Repository, remote string, warn func(message string)) Repository {
+	return &remoteTagsRepository{Repository: r, remote: remote, warn: warn}
+}
+
+func (r *remoteTagsRepository) remoteTags(local map[string]string) (map[string]string, error) {
+	if r.tags != nil {
+		return r.tags, nil
+	}
+
+	tags, err := r.Repository.RemoteTags(r.remote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the tags of %s: %v", r.remote, err)
+	}
+
+	names := make([]string, 0, len(local))
+	for name := range local {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		remoteHash, ok := tags[name]
+		switch {
+		case !ok:
+			r.warn(fmt.Sprintf("tag %s only exists locally, not on %s", name, r.remote))
+		case remoteHash != local[name]:
+			r.warn(fmt.Sprintf("tag %s points to %s on %s, but to %s locally",
+				name, shortHash(remoteHash), r.remote, shortHash(local[name])))
+		}
+	}
+
+	r.tags = tags
+	return tags, nil
+}
+
+func (r *remoteTagsRepository) TagCommits() (map[string]string, error) {
+	local, err := r.Repository.TagCommits()
+	if err != nil {
+		return nil, err
+	}
+	remote, err := r.remoteTags(local)
+	if err != nil {
+		return nil, err
+	}
+
+	// Local tags take precedence, because new tags are created locally
+	result := maps.Clone(remote)
+	maps.Copy(result, local)
+	return result, nil
+}
+
+func (r *remoteTagsRepository) Tags() ([]string, error) {
+	commits, err := r.TagCommits()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(commits))
+	for name := range commits {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (r *remoteTagsRepository) TagsAt(revision string) ([]string, error) {
+	commit, err := r.Resolve(revision)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := r.TagCommits()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0)
+	for name, target := range commits {
+		if target == commit {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// MergedTags combines the merged local tags with the remote tags pointing at an ancestor of the revision.
+// Remote tags of commits, which do not exist locally (e.g. beyond the depth of a shallow clone), are left out.
+func (r *remoteTagsRepository) MergedTags(revision string) ([]string, error) {
+	merged, err := r.Repository.MergedTags(revision)
+	if err != nil {
+		return nil, err
+	}
+	local, err := r.Repository.TagCommits()
+	if err != nil {
+		return nil, err
+	}
+	remote, err := r.remoteTags(local)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := r.Resolve(revision)
+	if err != nil {
+		return nil, err
+	}
+
+	// The commit is an ancestor, when no commit of it is missing in the history of the revision
+	ancestors := map[string]bool{commit: true}
+	result := merged
+	for name, target := range remote {
+		if _, ok := local[name]; ok {
+			continue
+		}
+		ancestor, ok := ancestors[target]
+		if !ok {
+			missing, err := r.Repository.CountCommits(commit, target, "")
+			ancestor = err == nil && missing == 0
+			ancestors[target] = ancestor
+		}
+		if ancestor {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -0,0 +1,75 @@
+package repository_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/MatthiasSchild/tagger/repository"
+)
+
+func TestWithRemoteTags(t *testing.T) {
+	memory := repository.NewMemory()
+	first, _ := memory.Resolve("HEAD")
+	_ = memory.CreateTag("v1.0.0", "HEAD", "v1.0.0")
+	second := memory.Commit("feature")
+	_ = memory.CreateTag("v1.1.0", "HEAD", "v1.1.0")
+	_ = memory.CreateTag("local", "HEAD", "local")
+
+	memory.SetRemoteTag("origin", "v1.0.0", first)
+	memory.SetRemoteTag("origin", "v1.1.0", first)
+	memory.SetRemoteTag("origin", "v1.2.0", second)
+
+	warnings := make([]string, 0)
+	repo := repository.WithRemoteTags(memory, "origin", func(message string) {
+		warnings = append(warnings, message)
+	})
+
+	tags, err := repo.Tags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(tags, []string{"local", "v1.0.0", "v1.1.0", "v1.2.0"}) {
+		t.Errorf("unexpected tags %v", tags)
+	}
+
+	// Local tags take precedence over the remote ones
+	tags, _ = repo.TagsAt("HEAD")
+	if !slices.Equal(tags, []string{"local", "v1.1.0", "v1.2.0"}) {
+		t.Errorf("unexpected tags at HEAD %v", tags)
+	}
+
+	expected := []string{
+		"tag local only exists locally, not on origin",
+		"tag v1.1.0 points to " + first[:7] + " on origin, but to " + second[:7] + " locally",
+	}
+	if !slices.Equal(warnings, expected) {
+		t.Errorf("unexpected warnings %q", warnings)
+	}
+
+	// Remote tags are merged, when their commit is an ancestor of the revision
+	merged, err := repo.MergedTags("HEAD~1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(merged, []string{"v1.0.0"}) {
+		t.Errorf("unexpected merged tags of HEAD~1 %v", merged)
+	}
+	merged, _ = repo.MergedTags("HEAD")
+	if !slices.Equal(merged, []string{"local", "v1.0.0", "v1.1.0", "v1.2.0"}) {
+		t.Errorf("unexpected merged tags of HEAD %v", merged)
+	}
+
+	// Commits beyond the depth of a shallow clone do not exist locally
+	memory.SetRemoteTag("shallow", "v0.9.0", "0123456789012345678901234567890123456789")
+	merged, err = repository.WithRemoteTags(memory, "shallow", func(string) {}).MergedTags("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(merged, []string{"local", "v1.0.0", "v1.1.0"}) {
+		t.Errorf("unexpected merged tags without the remote commits %v", merged)
+	}
+
+	if _, err := repository.WithRemoteTags(memory, "upstream", func(string) {}).Tags(); err == nil {
+		t.Error("an unknown remote should fail")
+	}
+}
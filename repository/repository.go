@@ -0,0 +1,88 @@
+// Package repository provides access to the git repository tagger works on.
+// There are two backends: the default one running the git binary and an opt-in native one
+// reading and writing the .git directory in-process. Memory is an in-memory fake for tests and tools.
+package repository
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// Repository contains the git operations used by tagger.
+// Revisions can be commit hashes, HEAD, branch or tag names, optionally with ~n or ^n suffixes.
+type Repository interface {
+	// Tags returns the names of all tags
+	Tags() ([]string, error)
+	// TagsAt returns the names of the tags pointing at the commit of the revision.
+	// Annotated tags are peeled to their commit.
+	TagsAt(revision string) ([]string, error)
+	// MergedTags returns the names of the tags pointing at the commit of the revision or one of its ancestors
+	MergedTags(revision string) ([]string, error)
+	// TagCommits returns the commit hashes of all tags by name.
+	// Annotated tags are peeled to their commit.
+	TagCommits() (map[string]string, error)
+	// RemoteTags returns the commit hashes of the tags of a remote (name or URL) by name,
+	// without fetching them. The remote is always queried with the git binary.
+	RemoteTags(remote string) (map[string]string, error)
+	// Resolve returns the commit hash of the revision
+	Resolve(revision string) (string, error)
+	// Branch returns the name of the checked out branch, empty when HEAD is detached
+	Branch() (string, error)
+	// Behind returns the upstream of the checked out branch (e.g. origin/main) and the number of its commits,
+	// which are not contained in HEAD. The upstream is empty, when none is configured.
+	// Nothing is fetched, so HEAD is compared with the last fetched state of the upstream.
+	Behind() (string, int, error)
+	// CreateTag creates an annotated tag at the commit of the revision
+	CreateTag(name string, revision string, message string) error
+	// MoveTag points the tag at the commit of the revision, creating it or replacing an existing one.
+	// With a message an annotated tag is created, otherwise a lightweight one.
+	MoveTag(name string, revision string, message string) error
+	// DeleteTag deletes a local tag
+	DeleteTag(name string) error
+	// CommitAll stages all changes (including untracked, not ignored files) and commits them
+	CommitAll(message string) error
+	// HasUncommittedChanges checks, if tracked files differ from HEAD
+	HasUncommittedChanges() (bool, error)
+	// CountCommits counts the commits reachable from the revision but not from since, which touch the path.
+	// When since is empty, all commits reachable from the revision are considered.
+	// The path is relative to the working directory, an empty path matches every commit.
+	CountCommits(since string, revision string, path string) (int, error)
+	// Messages returns the messages of the commits reachable from the revision but not from since.
+	// When since is empty, all commits reachable from the revision are considered. The order is not specified.
+	Messages(since string, revision string) ([]string, error)
+	// Commits returns the commits of the first parent history of the revision, which change the path
+	// compared to their first parent, from the newest to the oldest.
+	// The path is relative to the working directory, an empty path matches every commit.
+	Commits(revision string, path string) ([]string, error)
+	// Files returns the files of the commit of the revision within the working directory,
+	// as they would be checked out. Paths are slash separated and relative to the working directory.
+	Files(revision string) (fs.FS, error)
+	// ResetHard points the checked out branch (or the detached HEAD) at the revision
+	// and discards all changes of tracked files. Use HEAD to only discard the changes.
+	ResetHard(revision string) error
+	// Pushed checks, if the commit is contained in a remote tracking branch (refs/remotes/...)
+	Pushed(hash string) (bool, error)
+	// DeleteRemoteTag deletes a tag of a remote (name or URL), it is always pushed with the git binary
+	DeleteRemoteTag(remote string, name string) error
+	// GitDir returns the path of the .git directory (the common one of linked work trees),
+	// it is empty for repositories without one
+	GitDir() string
+}
+
+// Backends, which can be passed to Open
+const (
+	BackendNative = "native"
+	BackendExec   = "exec"
+)
+
+// Open opens the repository containing the directory with the given backend
+func Open(dir string, backend string) (Repository, error) {
+	switch backend {
+	case BackendExec, "":
+		return NewExec(dir), nil
+	case BackendNative:
+		return OpenNative(dir)
+	default:
+		return nil, fmt.Errorf("unknown git backend %s, use exec or native", backend)
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/MatthiasSchild/tagger/release"
+	"github.com/MatthiasSchild/tagger/targets"
+	"github.com/MatthiasSchild/tagger/version"
+)
+
+// statusReport is the release state of the repository shown by "tagger status"
+type statusReport struct {
+	Branch        string       `json:"branch"`
+	Upstream      string       `json:"upstream,omitempty"`
+	Behind        int          `json:"behind"`
+	Dirty         bool         `json:"dirty"`
+	Head          string       `json:"head"`
+	LatestTag     string       `json:"latestTag,omitempty"`
+	LatestCommit  string       `json:"latestCommit,omitempty"`
+	CommitsSince  int          `json:"commitsSince"`
+	SuggestedBump string       `json:"suggestedBump,omitempty"`
+	NextVersion   string       `json:"nextVersion"`
+	Files         []statusFile `json:"files"`
+	Releasable    bool         `json:"releasable"`
+	Problems      []string     `json:"problems"`
+}
+
+// statusFile is a detected version file and its version
+type statusFile struct {
+	Path    string `json:"path"`
+	Target  string `json:"target"`
+	Version string `json:"version"`
+	Status  string `json:"status"`
+}
+
+// buildStatus collects the release state of the repository
+func buildStatus() (statusReport, error) {
+	report := statusReport{Files: make([]statusFile, 0), Problems: make([]string, 0)}
+
+	var err error
+	report.Head, err = getCurrentGitHash()
+	if err != nil {
+		return statusReport{}, fmt.Errorf("failed to resolve HEAD: %v", err)
+	}
+	report.Branch, err = repo.Branch()
+	if err != nil {
+		return statusReport{}, fmt.Errorf("failed to read the current branch: %v", err)
+	}
+	report.Upstream, report.Behind, err = repo.Behind()
+	if err != nil {
+		return statusReport{}, fmt.Errorf("failed to compare with the upstream: %v", err)
+	}
+	report.Dirty, err = hasUncommittedChanges()
+	if err != nil {
+		return statusReport{}, fmt.Errorf("failed to check, if uncommitted changes exist: %v", err)
+	}
+
+	latestTag, since, err := getLatestGitTag(tagPrefix)
+	if err != nil {
+		return statusReport{}, fmt.Errorf("failed to fetch git tags: %v", err)
+	}
+	if latestTag != nil {
+		report.LatestTag = since
+		report.LatestCommit, _ = repo.Resolve(since)
+	}
+
+	report.CommitsSince, err = countCommitsSince(since, ".")
+	if err != nil {
+		return statusReport{}, fmt.Errorf("failed to count the commits: %v", err)
+	}
+	messages, err := repo.Messages(since, "HEAD")
+	if err != nil {
+		return statusReport{}, fmt.Errorf("failed to read the commit messages: %v", err)
+	}
+	report.SuggestedBump = release.SuggestBump(messages)
+
+	if latestTag != nil {
+		report.NextVersion = latestTag.Bump(report.SuggestedBump).String()
+	} else {
+		initial, _, err := initialVersion()
+		if err != nil {
+			return statusReport{}, err
+		}
+		report.NextVersion = initial.String()
+	}
+
+	for _, manifest := range targets.DetectManifests(versionFiles) {
+		file := statusFile{Path: manifest.Path, Target: manifest.Target, Version: manifest.Version, Status: "ok"}
+		if manifest.Version == "" {
+			file.Status = "no version"
+		} else if latestTag != nil && manifest.Version != latestTag.Version() {
+			file.Status = "differs from latest tag"
+		}
+		report.Files = append(report.Files, file)
+	}
+
+	// The configured pre-flight checks decide, if HEAD is releasable.
+	// A tagged HEAD and a HEAD without changes are never releasable.
+	failures, err := checkPreflight(latestTag)
+	if err != nil {
+		return statusReport{}, err
+	}
+	for _, failure := range failures {
+		report.Problems = append(report.Problems, failure.Message)
+	}
+	headTags, err := getHeadGitTags()
+	if err != nil {
+		return statusReport{}, fmt.Errorf("failed to fetch git tags of HEAD: %v", err)
+	}
+	if len(headTags) > 0 && !preflightEnabled(ruleTagged) {
+		report.Problems = append(report.Problems, fmt.Sprintf("HEAD is already tagged with %s", version.Latest(headTags)))
+	}
+	if latestTag != nil && report.CommitsSince == 0 && !preflightEnabled(ruleUnchanged) {
+		report.Problems = append(report.Problems, fmt.Sprintf("there are no commits since %s", latestTag))
+	}
+	report.Releasable = len(report.Problems) == 0
+
+	return report, nil
+}
+
+// printStatus prints the report as JSON or as readable table
+func printStatus(report statusReport, asJson bool) error {
+	if asJson {
+		content, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(content))
+		return nil
+	}
+
+	branch := report.Branch
+	switch {
+	case branch == "":
+		branch = "detached HEAD"
+	case report.Upstream == "":
+		branch += " (no upstream)"
+	case report.Behind > 0:
+		branch += fmt.Sprintf(" (%d commit(s) behind %s)", report.Behind, report.Upstream)
+	default:
+		branch += fmt.Sprintf(" (up to date with %s)", report.Upstream)
+	}
+	workTree := "clean"
+	if report.Dirty {
+		workTree = "uncommitted changes"
+	}
+	latest := "-"
+	if report.LatestTag != "" {
+		latest = fmt.Sprintf("%s (%s)", report.LatestTag, shortCommit(report.LatestCommit))
+	}
+	suggested := report.NextVersion
+	if report.SuggestedBump != "" {
+		suggested = fmt.Sprintf("%s (%s)", report.NextVersion, report.SuggestedBump)
+	}
+	releasable := "yes"
+	if !report.Releasable {
+		releasable = "no"
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "Branch:\t%s\n", branch)
+	fmt.Fprintf(writer, "HEAD:\t%s\n", shortCommit(report.Head))
+	fmt.Fprintf(writer, "Work tree:\t%s\n", workTree)
+	fmt.Fprintf(writer, "Latest tag:\t%s\n", latest)
+	fmt.Fprintf(writer, "Commits since:\t%d\n", report.CommitsSince)
+	fmt.Fprintf(writer, "Next version:\t%s\n", suggested)
+	fmt.Fprintf(writer, "Releasable:\t%s\n", releasable)
+	for _, problem := range report.Problems {
+		fmt.Fprintf(writer, "\t- %s\n", problem)
+	}
+	err := writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	if len(report.Files) == 0 {
+		fmt.Println("\nNo version files found")
+		return nil
+	}
+	fmt.Println()
+	writer = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "FILE\tTARGET\tVERSION\tSTATUS")
+	for _, file := range report.Files {
+		value := file.Version
+		if value == "" {
+			value = "-"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", file.Path, file.Target, value, file.Status)
+	}
+	return writer.Flush()
+}
+
+// shortCommit returns the abbreviated commit hash
+func shortCommit(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
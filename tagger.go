@@ -1,12 +1,31 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"os"
 )
 
+// ExitCodeError is returned by commands, which need a specific exit code (e.g. for CI)
+type ExitCodeError struct {
+	Code    int
+	Message string
+}
+
+func (e *ExitCodeError) Error() string {
+	return e.Message
+}
+
 func main() {
 	err := RootCmd.Execute()
+	if journalErr := saveJournal(); journalErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save the journal: %v\n", journalErr)
+	}
 	if err != nil {
+		var exitCodeErr *ExitCodeError
+		if errors.As(err, &exitCodeErr) {
+			os.Exit(exitCodeErr.Code)
+		}
 		os.Exit(1)
 	}
 }
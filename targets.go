@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/MatthiasSchild/tagger/targets"
+)
+
+// targetOptions returns the settings of the targets from the flags and the config file
+func targetOptions() targets.Options {
+	return targets.Options{
+		ChartBump:   flagChartBump,
+		AppVersion:  flagAppVersion,
+		BuildNumber: flagBuildNumber,
+		Generate:    config.Generate,
+		Custom:      config.Targets,
+		Report: func(message string) {
+			fmt.Println(message)
+		},
+	}
+}
+
+// writeVersionToTarget writes the version into the files of a built-in or custom target.
+// Generated files get the commit of the options, HEAD when it is not set.
+func writeVersionToTarget(target string, tag Tag, options targets.Options) error {
+	if target == "generate" && options.Commit == "" {
+		commit, err := getCurrentGitHash()
+		if err != nil {
+			return fmt.Errorf("could not get current hash: %v", err)
+		}
+		options.Commit = commit
+	}
+
+	return targets.Write(target, tag, options)
+}
+
+// readableTargets returns the targets with a version reader.
+// These are the targets of the config file or the detected ones, when none are configured.
+func readableTargets() []string {
+	result := make([]string, 0)
+	for _, target := range writableTargets() {
+		if _, ok := targets.Readers[target]; ok {
+			result = append(result, target)
+		}
+	}
+	return result
+}
+
+// writableTargets returns the targets of the config file or the detected ones, when none are configured
+func writableTargets() []string {
+	if len(config.Write) > 0 {
+		return config.Write
+	}
+	return targets.Detect(versionFiles)
+}
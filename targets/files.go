@@ -0,0 +1,732 @@
+package targets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/MatthiasSchild/tagger/utils"
+	"github.com/MatthiasSchild/tagger/version"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ReadPackageJson reads the version of the package.json of the file system
+func ReadPackageJson(fsys fs.FS) (version.Tag, error) {
+	content, err := fs.ReadFile(fsys, "package.json")
+	if err != nil {
+		return version.Tag{}, err
+	}
+
+	packageData := &struct {
+		Version string `json:"version"`
+	}{}
+
+	err = json.Unmarshal(content, packageData)
+	if err != nil {
+		return version.Tag{}, err
+	}
+
+	tag, err := version.Parse(packageData.Version)
+	if err != nil {
+		return version.Tag{}, fmt.Errorf("version in package.json must have format '1.2.3'")
+	}
+	return tag.Clone(), nil
+}
+
+// WritePackageJson writes the version into the package.json
+func WritePackageJson(tag version.Tag) error {
+	content, err := os.ReadFile("package.json")
+	if err != nil {
+		return err
+	}
+
+	// Replace the version
+	re := regexp.MustCompile(`"version":\s*"[^"]*"`)
+	newVersion := fmt.Sprintf("%d.%d.%d", tag.Major, tag.Minor, tag.Patch)
+	updatedContent := re.ReplaceAllString(string(content), fmt.Sprintf(`"version": "%s"`, newVersion))
+
+	err = os.WriteFile("package.json", []byte(updatedContent), 0644)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReadPubspecYaml reads the version and build number of the pubspec.yaml of the file system
+func ReadPubspecYaml(fsys fs.FS) (version.Tag, int, error) {
+	content, err := fs.ReadFile(fsys, "pubspec.yaml")
+	if err != nil {
+		return version.Tag{}, 0, err
+	}
+
+	packageData := &struct {
+		Version string `json:"version"`
+	}{}
+
+	err = yaml.Unmarshal(content, packageData)
+	if err != nil {
+		return version.Tag{}, 0, err
+	}
+
+	tag, err := version.Parse(packageData.Version)
+	if err != nil {
+		return version.Tag{}, 0, fmt.Errorf("version in pubspec.yaml must have format '1.2.3+4'")
+	}
+	build, _ := strconv.Atoi(tag.PlusAddition + tag.MinusAddition)
+
+	return tag.Clone(), build, nil
+}
+
+// WritePubspecYaml writes the version into the pubspec.yaml.
+// When increment is set, the build number is incremented.
+func WritePubspecYaml(tag version.Tag, increment bool) error {
+	content, err := os.ReadFile("pubspec.yaml")
+	if err != nil {
+		return err
+	}
+
+	// Replace the version
+	re := regexp.MustCompile(`version:\s*(\d+\.\d+\.\d+)\+(\d+)`)
+	newVersion := fmt.Sprintf("%d.%d.%d", tag.Major, tag.Minor, tag.Patch)
+	updatedContent := re.ReplaceAllStringFunc(string(content), func(match string) string {
+		matches := re.FindStringSubmatch(match)
+		if len(matches) > 2 {
+			buildNumber, _ := strconv.Atoi(matches[2])
+			if increment {
+				buildNumber++
+			}
+			return fmt.Sprintf("version: %s+%d", newVersion, buildNumber)
+		}
+		return match
+	})
+
+	err = os.WriteFile("pubspec.yaml", []byte(updatedContent), 0644)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// WritePubspecYamlBuild writes the version and build number into the pubspec.yaml
+func WritePubspecYamlBuild(tag version.Tag, buildNumber int) error {
+	content, err := os.ReadFile("pubspec.yaml")
+	if err != nil {
+		return err
+	}
+
+	// Replace the version
+	re := regexp.MustCompile(`version:\s*([\d+-.]+)`)
+	newVersion := fmt.Sprintf("%d.%d.%d", tag.Major, tag.Minor, tag.Patch)
+	updatedContent := re.ReplaceAllStringFunc(string(content), func(match string) string {
+		return fmt.Sprintf("version: %s+%d", newVersion, buildNumber)
+	})
+
+	err = os.WriteFile("pubspec.yaml", []byte(updatedContent), 0644)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReadCargoToml reads the package version of the Cargo.toml of the file system
+func ReadCargoToml(fsys fs.FS) (version.Tag, error) {
+	content, err := fs.ReadFile(fsys, "Cargo.toml")
+	if err != nil {
+		return version.Tag{}, err
+	}
+
+	packageData := &struct {
+		Package struct {
+			Version string `toml:"version"`
+		} `toml:"package"`
+	}{}
+
+	err = toml.Unmarshal(content, packageData)
+	if err != nil {
+		return version.Tag{}, err
+	}
+
+	tag, err := version.Parse(packageData.Package.Version)
+	if err != nil {
+		return version.Tag{}, fmt.Errorf("version in Cargo.toml must have format '1.2.3'")
+	}
+	return tag.Clone(), nil
+}
+
+// WriteCargoToml writes the package version into the Cargo.toml
+func WriteCargoToml(tag version.Tag) error {
+	content, err := os.ReadFile("Cargo.toml")
+	if err != nil {
+		return err
+	}
+
+	updatedContent := utils.UpdateVersionInToml(
+		string(content),
+		tag.Version(),
+	)
+
+	// // Replace the version
+	// re := regexp.MustCompile(`"version":\s*"[^"]*"`)
+	// newVersion := fmt.Sprintf("%d.%d.%d", tag.Major, tag.Minor, tag.Patch)
+	// updatedContent := re.ReplaceAllString(string(content), fmt.Sprintf(`"version": "%s"`, newVersion))
+
+	err = os.WriteFile("Cargo.toml", []byte(updatedContent), 0644)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReadChartYaml reads the chart version of the Chart.yaml of the file system
+func ReadChartYaml(fsys fs.FS) (version.Tag, error) {
+	content, err := fs.ReadFile(fsys, "Chart.yaml")
+	if err != nil {
+		return version.Tag{}, err
+	}
+
+	chartData := &struct {
+		Version string `yaml:"version"`
+	}{}
+
+	err = yaml.Unmarshal(content, chartData)
+	if err != nil {
+		return version.Tag{}, err
+	}
+
+	tag, err := version.Parse(chartData.Version)
+	if err != nil {
+		return version.Tag{}, fmt.Errorf("version in Chart.yaml must have format '1.2.3'")
+	}
+	return tag.Clone(), nil
+}
+
+// ReadChartAppVersion reads the appVersion of the Chart.yaml of the file system.
+// The chart version is bumped independently of the tags, so the appVersion is the version of the release.
+func ReadChartAppVersion(fsys fs.FS) (version.Tag, error) {
+	content, err := fs.ReadFile(fsys, "Chart.yaml")
+	if err != nil {
+		return version.Tag{}, err
+	}
+
+	chartData := &struct {
+		AppVersion string `yaml:"appVersion"`
+	}{}
+
+	err = yaml.Unmarshal(content, chartData)
+	if err != nil {
+		return version.Tag{}, err
+	}
+	if chartData.AppVersion == "" {
+		return version.Tag{}, fmt.Errorf("no appVersion found in Chart.yaml")
+	}
+
+	tag, err := version.Parse(chartData.AppVersion)
+	if err != nil {
+		return version.Tag{}, fmt.Errorf("appVersion in Chart.yaml must have format '1.2.3'")
+	}
+	return tag.Clone(), nil
+}
+
+// WriteChartYaml writes the chart version into the Chart.yaml.
+// When appVersion is not empty, the appVersion will be updated as well.
+// A leading "v" of the previous appVersion is kept.
+// Afterwards the versions of local subcharts (file://) are updated in the Chart.yaml and Chart.lock,
+// whose digest is updated as well.
+func WriteChartYaml(chartVersion version.Tag, appVersion string) error {
+	content, err := os.ReadFile("Chart.yaml")
+	if err != nil {
+		return err
+	}
+
+	updatedContent := utils.UpdateYamlValue(string(content), "version", chartVersion.Version())
+	if appVersion != "" {
+		if strings.HasPrefix(utils.ReadYamlValue(updatedContent, "appVersion"), "v") {
+			appVersion = "v" + appVersion
+		}
+		updatedContent = utils.UpdateYamlValue(updatedContent, "appVersion", appVersion)
+	}
+
+	subchartVersions, err := readLocalSubchartVersions(content)
+	if err != nil {
+		return err
+	}
+	updatedContent = utils.UpdateYamlDependencyVersions(updatedContent, subchartVersions)
+
+	err = os.WriteFile("Chart.yaml", []byte(updatedContent), 0644)
+	if err != nil {
+		return err
+	}
+
+	lockContent, err := os.ReadFile("Chart.lock")
+	if errors.Is(err, os.ErrNotExist) || len(subchartVersions) == 0 {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	updatedLockContent := utils.UpdateYamlDependencyVersions(string(lockContent), subchartVersions)
+	digest, err := chartLockDigest(updatedContent, updatedLockContent)
+	if err != nil {
+		return fmt.Errorf("failed to compute the digest of Chart.lock: %v", err)
+	}
+	updatedLockContent = utils.UpdateYamlValue(updatedLockContent, "digest", digest)
+	err = os.WriteFile("Chart.lock", []byte(updatedLockContent), 0644)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// chartDependency is a dependency of a Chart.yaml or Chart.lock.
+// Its JSON encoding matches the one of helm, which is hashed for the digest of the Chart.lock.
+type chartDependency struct {
+	Name         string   `yaml:"name" json:"name"`
+	Version      string   `yaml:"version" json:"version,omitempty"`
+	Repository   string   `yaml:"repository" json:"repository"`
+	Condition    string   `yaml:"condition" json:"condition,omitempty"`
+	Tags         []string `yaml:"tags" json:"tags,omitempty"`
+	Enabled      bool     `yaml:"enabled" json:"enabled,omitempty"`
+	ImportValues []any    `yaml:"import-values" json:"import-values,omitempty"`
+	Alias        string   `yaml:"alias" json:"alias,omitempty"`
+}
+
+// chartLockDigest computes the digest of the Chart.lock like helm does:
+// the SHA-256 of the JSON encoded dependencies of the Chart.yaml and the Chart.lock.
+func chartLockDigest(chartContent string, lockContent string) (string, error) {
+	chartData := &struct {
+		Dependencies []*chartDependency `yaml:"dependencies"`
+	}{}
+	err := yaml.Unmarshal([]byte(chartContent), chartData)
+	if err != nil {
+		return "", err
+	}
+
+	lockData := &struct {
+		Dependencies []*chartDependency `yaml:"dependencies"`
+	}{}
+	err = yaml.Unmarshal([]byte(lockContent), lockData)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal([2][]*chartDependency{chartData.Dependencies, lockData.Dependencies})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// readLocalSubchartVersions reads the versions of all dependencies of the chart,
+// which are referenced with a "file://" repository, from their own Chart.yaml.
+func readLocalSubchartVersions(chartContent []byte) (map[string]string, error) {
+	chartData := &struct {
+		Dependencies []struct {
+			Name       string `yaml:"name"`
+			Repository string `yaml:"repository"`
+		} `yaml:"dependencies"`
+	}{}
+
+	err := yaml.Unmarshal(chartContent, chartData)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string)
+	for _, dependency := range chartData.Dependencies {
+		if !strings.HasPrefix(dependency.Repository, "file://") {
+			continue
+		}
+
+		path := filepath.Join(strings.TrimPrefix(dependency.Repository, "file://"), "Chart.yaml")
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read subchart %s: %v", dependency.Name, err)
+		}
+
+		value := utils.ReadYamlValue(string(content), "version")
+		if value == "" {
+			return nil, fmt.Errorf("subchart %s has no version", dependency.Name)
+		}
+		result[dependency.Name] = value
+	}
+
+	return result, nil
+}
+
+// FindDotnetProjectFiles returns the Directory.Build.props and all csproj files
+// within the current directory and its subdirectories.
+// The build output directories (bin, obj) are skipped.
+func FindDotnetProjectFiles() ([]string, error) {
+	return findDotnetProjectFiles(os.DirFS("."))
+}
+
+// findDotnetProjectFiles returns the .NET project files within the file system, see FindDotnetProjectFiles
+func findDotnetProjectFiles(fsys fs.FS) ([]string, error) {
+	result := make([]string, 0)
+
+	err := fs.WalkDir(fsys, ".", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			switch entry.Name() {
+			case ".git", "bin", "obj", "node_modules":
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if path == "Directory.Build.props" || filepath.Ext(path) == ".csproj" {
+			result = append(result, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no Directory.Build.props or csproj file found")
+	}
+	return result, nil
+}
+
+// ReadDotnetProject reads the version of the .NET project files of the file system, which must not differ
+func ReadDotnetProject(fsys fs.FS) (version.Tag, error) {
+	paths, err := findDotnetProjectFiles(fsys)
+	if err != nil {
+		return version.Tag{}, err
+	}
+
+	var result version.Tag
+	versionPath := ""
+	for _, path := range paths {
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return version.Tag{}, err
+		}
+
+		value := utils.ReadXmlElement(string(content), "Version")
+		if value == "" {
+			value = utils.ReadXmlElement(string(content), "VersionPrefix")
+		}
+		if value == "" {
+			continue
+		}
+
+		parsed, err := version.Parse(value)
+		if err != nil {
+			return version.Tag{}, fmt.Errorf("version in %s must have format '1.2.3'", path)
+		}
+		tag := parsed.Clone()
+
+		if versionPath != "" && !tag.Equals(result) {
+			return version.Tag{}, fmt.Errorf("versions in %s and %s differ", versionPath, path)
+		}
+		result = tag
+		versionPath = path
+	}
+
+	if versionPath == "" {
+		return version.Tag{}, fmt.Errorf("no <Version> or <VersionPrefix> found in the project files")
+	}
+	return result, nil
+}
+
+// WriteDotnetProject writes the version into all existing version elements
+// of the Directory.Build.props and the csproj files.
+// AssemblyVersion and FileVersion get a fourth part, the revision.
+// With IncrementBuildNumber, the highest existing revision will be incremented,
+// with KeepBuildNumber it is kept.
+func WriteDotnetProject(tag version.Tag, revision int) error {
+	paths, err := FindDotnetProjectFiles()
+	if err != nil {
+		return err
+	}
+
+	contents := make(map[string]string)
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		contents[path] = string(content)
+	}
+
+	if revision < 0 {
+		highest := -1
+		for _, content := range contents {
+			for _, element := range []string{"AssemblyVersion", "FileVersion"} {
+				parts := strings.Split(utils.ReadXmlElement(content, element), ".")
+				if len(parts) != 4 {
+					continue
+				}
+				current, err := strconv.Atoi(parts[3])
+				if err == nil && current > highest {
+					highest = current
+				}
+			}
+		}
+		if revision == KeepBuildNumber {
+			revision = max(highest, 0)
+		} else {
+			revision = highest + 1
+		}
+	}
+
+	prefix := tag.Version()
+	value := prefix
+	if len(tag.MinusAddition) > 0 {
+		value += "-" + tag.MinusAddition
+	}
+	fourPartVersion := fmt.Sprintf("%s.%d", prefix, revision)
+
+	for _, path := range paths {
+		content := contents[path]
+		content = utils.UpdateXmlElement(content, "Version", value)
+		content = utils.UpdateXmlElement(content, "VersionPrefix", prefix)
+		content = utils.UpdateXmlElement(content, "VersionSuffix", tag.MinusAddition)
+		content = utils.UpdateXmlElement(content, "AssemblyVersion", fourPartVersion)
+		content = utils.UpdateXmlElement(content, "FileVersion", fourPartVersion)
+
+		if content == contents[path] {
+			continue
+		}
+		err = os.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindXcodeProjectFiles returns all project.pbxproj files of the Xcode projects
+// and all Info.plist files within the current directory and its subdirectories.
+// Dependencies and build output directories are skipped.
+func FindXcodeProjectFiles() ([]string, []string, error) {
+	return findXcodeProjectFiles(os.DirFS("."))
+}
+
+// findXcodeProjectFiles returns the Xcode project files within the file system, see FindXcodeProjectFiles
+func findXcodeProjectFiles(fsys fs.FS) ([]string, []string, error) {
+	projects := make([]string, 0)
+	plists := make([]string, 0)
+
+	err := fs.WalkDir(fsys, ".", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			switch entry.Name() {
+			case ".git", "Pods", "Carthage", "DerivedData", "build", "node_modules":
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if entry.Name() == "project.pbxproj" && filepath.Ext(filepath.Dir(path)) == ".xcodeproj" {
+			projects = append(projects, path)
+		} else if entry.Name() == "Info.plist" || strings.HasSuffix(entry.Name(), "-Info.plist") {
+			plists = append(plists, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(projects) == 0 && len(plists) == 0 {
+		return nil, nil, fmt.Errorf("no project.pbxproj or Info.plist found")
+	}
+	return projects, plists, nil
+}
+
+// xcodeVersionRegex matches a marketing version of Xcode, which has one to three parts (e.g. 1.0 or 1.2.3)
+var xcodeVersionRegex = regexp.MustCompile(`^(\d+)(?:\.(\d+))?(?:\.(\d+))?$`)
+
+// ReadXcodeProject reads the marketing version of the Xcode projects of the file system, which must not differ.
+// Values referencing build settings (e.g. $(FLUTTER_BUILD_NAME) of Flutter projects) are skipped,
+// missing parts of short versions are 0 (e.g. 1.0 is 1.0.0).
+func ReadXcodeProject(fsys fs.FS) (version.Tag, error) {
+	projects, plists, err := findXcodeProjectFiles(fsys)
+	if err != nil {
+		return version.Tag{}, err
+	}
+
+	values := make([]string, 0)
+	for _, path := range projects {
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return version.Tag{}, err
+		}
+		values = append(values, utils.ReadPbxprojSettings(string(content), "MARKETING_VERSION")...)
+	}
+	for _, path := range plists {
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return version.Tag{}, err
+		}
+		values = append(values, utils.ReadPlistString(string(content), "CFBundleShortVersionString"))
+	}
+
+	var result version.Tag
+	first := ""
+	for _, value := range values {
+		if value == "" || utils.IsBuildSettingReference(value) {
+			continue
+		}
+		groups := xcodeVersionRegex.FindStringSubmatch(value)
+		if groups == nil {
+			return version.Tag{}, fmt.Errorf("version %s in the Xcode project must have format '1.2.3'", value)
+		}
+		major, _ := strconv.Atoi(groups[1])
+		minor, _ := strconv.Atoi(groups[2])
+		patch, _ := strconv.Atoi(groups[3])
+		tag := version.Tag{Major: major, Minor: minor, Patch: patch}
+
+		if first == "" {
+			result = tag
+			first = value
+		} else if !tag.Equals(result) {
+			return version.Tag{}, fmt.Errorf("versions in the Xcode project differ: %s, %s", first, value)
+		}
+	}
+
+	if first == "" {
+		return version.Tag{}, fmt.Errorf("no MARKETING_VERSION or CFBundleShortVersionString found")
+	}
+	return result, nil
+}
+
+// WriteXcodeProject writes the version into MARKETING_VERSION of all build configurations
+// and CFBundleShortVersionString of all Info.plist files.
+// CURRENT_PROJECT_VERSION and CFBundleVersion are set to the build number.
+// With IncrementBuildNumber, the highest existing build number will be incremented,
+// with KeepBuildNumber it is kept.
+func WriteXcodeProject(tag version.Tag, buildNumber int) error {
+	projects, plists, err := FindXcodeProjectFiles()
+	if err != nil {
+		return err
+	}
+
+	contents := make(map[string]string)
+	for _, path := range append(projects, plists...) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		contents[path] = string(content)
+	}
+
+	if buildNumber < 0 {
+		buildNumbers := make([]string, 0)
+		for _, path := range projects {
+			buildNumbers = append(buildNumbers, utils.ReadPbxprojSettings(contents[path], "CURRENT_PROJECT_VERSION")...)
+		}
+		for _, path := range plists {
+			buildNumbers = append(buildNumbers, utils.ReadPlistString(contents[path], "CFBundleVersion"))
+		}
+		if buildNumber == KeepBuildNumber {
+			buildNumber = max(utils.MaxBuildNumber(buildNumbers), 1)
+		} else {
+			buildNumber = utils.MaxBuildNumber(buildNumbers) + 1
+		}
+	}
+
+	value := tag.Version()
+	build := strconv.Itoa(buildNumber)
+
+	for _, path := range projects {
+		content := utils.UpdatePbxprojSetting(contents[path], "MARKETING_VERSION", value)
+		content = utils.UpdatePbxprojSetting(content, "CURRENT_PROJECT_VERSION", build)
+		contents[path] = content
+	}
+	for _, path := range plists {
+		content := utils.UpdatePlistString(contents[path], "CFBundleShortVersionString", value)
+		content = utils.UpdatePlistString(content, "CFBundleVersion", build)
+		contents[path] = content
+	}
+
+	for path, content := range contents {
+		err = os.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteCustomTarget replaces the version in all files of a custom target.
+// Every file matched by a glob must contain the pattern at least once,
+// otherwise no file will be written.
+// The number of replacements of every file is passed to report, when it is not nil.
+func WriteCustomTarget(tag version.Tag, target CustomTarget, report func(message string)) error {
+	contents := make(map[string]string)
+	paths := make([]string, 0)
+	counts := make(map[string]int)
+
+	for _, file := range target.Files {
+		re, err := regexp.Compile(file.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %s: %v", file.Pattern, err)
+		}
+
+		tmpl, err := template.New(file.Glob).Parse(file.Replace)
+		if err != nil {
+			return fmt.Errorf("invalid replacement %s: %v", file.Replace, err)
+		}
+		replacement := &strings.Builder{}
+		err = tmpl.Execute(replacement, tag)
+		if err != nil {
+			return fmt.Errorf("failed to render replacement %s: %v", file.Replace, err)
+		}
+
+		matches, err := filepath.Glob(file.Glob)
+		if err != nil {
+			return fmt.Errorf("invalid glob %s: %v", file.Glob, err)
+		}
+		if len(matches) == 0 {
+			return fmt.Errorf("no files match %s", file.Glob)
+		}
+
+		for _, path := range matches {
+			content, ok := contents[path]
+			if !ok {
+				rawContent, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				content = string(rawContent)
+				paths = append(paths, path)
+			}
+
+			count := len(re.FindAllStringIndex(content, -1))
+			if count == 0 {
+				return fmt.Errorf("pattern %s did not match in %s", file.Pattern, path)
+			}
+			contents[path] = re.ReplaceAllString(content, replacement.String())
+			counts[path] += count
+		}
+	}
+
+	for _, path := range paths {
+		err := os.WriteFile(path, []byte(contents[path]), 0644)
+		if err != nil {
+			return err
+		}
+		if report != nil {
+			report(fmt.Sprintf("%s: %d replacement(s)", path, counts[path]))
+		}
+	}
+	return nil
+}
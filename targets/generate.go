@@ -0,0 +1,221 @@
+package targets
+
+import (
+	"fmt"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/MatthiasSchild/tagger/version"
+)
+
+// generateTemplates contains the built-in templates for the version source files by language
+var generateTemplates = map[string]string{
+	"go": `// Code generated by tagger. DO NOT EDIT.
+
+package {{.Package}}
+
+const (
+	Version = "{{.Version}}"
+	Commit  = "{{.Commit}}"
+)
+`,
+	"ts": `// Generated by tagger. Do not edit.
+
+export const VERSION = "{{.Version}}";
+export const COMMIT = "{{.Commit}}";
+`,
+	"rust": `// Generated by tagger. Do not edit.
+
+pub const VERSION: &str = "{{.Version}}";
+pub const COMMIT: &str = "{{.Commit}}";
+`,
+	"dart": `// Generated by tagger. Do not edit.
+
+const String version = '{{.Version}}';
+const String commit = '{{.Commit}}';
+`,
+}
+
+// generatePaths contains the default paths of the version source files by language
+var generatePaths = map[string]string{
+	"go":   "version.go",
+	"ts":   "src/version.ts",
+	"rust": "src/version.rs",
+	"dart": "lib/version.dart",
+}
+
+// GenerateFile describes a version source file written by the generate target.
+// Either a built-in language (go, ts, rust, dart) or the path of a custom template must be set.
+type GenerateFile struct {
+	Language string `yaml:"language,omitempty"`
+	Template string `yaml:"template,omitempty"`
+	Path     string `yaml:"path,omitempty"`
+	Package  string `yaml:"package,omitempty"`
+}
+
+// generateData is passed to the templates of the version source files.
+// Commit is the hash of the commit, the release is based on.
+type generateData struct {
+	Tag     version.Tag
+	Version string
+	Commit  string
+	Package string
+}
+
+// GenerateFiles writes the version source files.
+// Commit is the hash of the commit, the release is based on.
+// The generated paths are passed to report, when it is not nil.
+func GenerateFiles(tag version.Tag, commit string, files []GenerateFile, report func(message string)) error {
+	if len(files) == 0 {
+		return fmt.Errorf("no files to generate configured")
+	}
+
+	for _, file := range files {
+		content, err := RenderVersionFile(tag, commit, file)
+		if err != nil {
+			return err
+		}
+
+		path := GeneratePath(file)
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			return err
+		}
+		if report != nil {
+			report(fmt.Sprintf("Generated %s", path))
+		}
+	}
+	return nil
+}
+
+// GeneratePath returns the path of a version source file, the default path of its language when none is set
+func GeneratePath(file GenerateFile) string {
+	if file.Path == "" {
+		return generatePaths[file.Language]
+	}
+	return file.Path
+}
+
+// RenderVersionFile renders the content of a version source file
+func RenderVersionFile(tag version.Tag, commit string, file GenerateFile) (string, error) {
+	var rawTemplate string
+	if file.Template != "" {
+		content, err := os.ReadFile(file.Template)
+		if err != nil {
+			return "", fmt.Errorf("failed to read template: %v", err)
+		}
+		rawTemplate = string(content)
+	} else {
+		var ok bool
+		rawTemplate, ok = generateTemplates[file.Language]
+		if !ok {
+			return "", fmt.Errorf("unknown language %s, use go, ts, rust, dart or a template", file.Language)
+		}
+	}
+
+	data := generateData{
+		Tag:     tag,
+		Version: tag.String(),
+		Commit:  commit,
+		Package: file.Package,
+	}
+	if data.Package == "" {
+		data.Package = "main"
+		if file.Path != "" && filepath.Dir(file.Path) != "." {
+			data.Package = goPackageName(filepath.Base(filepath.Dir(file.Path)))
+		}
+	}
+
+	tmpl, err := template.New(file.Path).Parse(rawTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %v", err)
+	}
+
+	result := &strings.Builder{}
+	err = tmpl.Execute(result, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render template: %v", err)
+	}
+	return result.String(), nil
+}
+
+var goModuleRegex = regexp.MustCompile(`(?m)^module\s+"?([^"\s]+)"?`)
+var goPackageRegex = regexp.MustCompile(`(?m)^package\s+(\w+)`)
+var goMajorVersionRegex = regexp.MustCompile(`^v[0-9]+$`)
+
+// goGenerateFile returns the version source file for the Go module of the file system.
+// It is placed in the root package of the module, so libraries get it in their own package instead of main.
+// Without Go files in the root, the package is named after the module path.
+func goGenerateFile(fsys fs.FS) (GenerateFile, error) {
+	content, err := fs.ReadFile(fsys, "go.mod")
+	if err != nil {
+		return GenerateFile{}, err
+	}
+	groups := goModuleRegex.FindStringSubmatch(string(content))
+	if groups == nil {
+		return GenerateFile{}, fmt.Errorf("no module in go.mod")
+	}
+
+	file := GenerateFile{Language: "go", Path: generatePaths["go"]}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return GenerateFile{}, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		source, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return GenerateFile{}, err
+		}
+		if groups := goPackageRegex.FindStringSubmatch(string(source)); groups != nil {
+			file.Package = groups[1]
+			return file, nil
+		}
+	}
+
+	// The major version suffix (example.com/lib/v2) is not part of the package name
+	module := groups[1]
+	if goMajorVersionRegex.MatchString(path.Base(module)) {
+		module = path.Dir(module)
+	}
+	file.Package = goPackageName(path.Base(module))
+	return file, nil
+}
+
+// goPackageName turns a name (e.g. of a directory) into a valid Go package name:
+// it is lowercased and other characters than letters, digits and underscores are dropped (my-tool becomes mytool)
+func goPackageName(name string) string {
+	result := &strings.Builder{}
+	for _, char := range strings.ToLower(name) {
+		if unicode.IsLetter(char) || unicode.IsDigit(char) || char == '_' {
+			result.WriteRune(char)
+		}
+	}
+
+	packageName := result.String()
+	if packageName == "" {
+		return "main"
+	}
+	if unicode.IsDigit(rune(packageName[0])) {
+		packageName = "_" + packageName
+	}
+	if token.IsKeyword(packageName) {
+		packageName += "_"
+	}
+	return packageName
+}
@@ -0,0 +1,84 @@
+package targets_test
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/MatthiasSchild/tagger/targets"
+	"github.com/MatthiasSchild/tagger/version"
+)
+
+func TestRenderVersionFile(t *testing.T) {
+	tag, err := version.Parse("v1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		file     targets.GenerateFile
+		expected string
+	}{
+		{targets.GenerateFile{Language: "go"}, "package main\n"},
+		{targets.GenerateFile{Language: "go", Path: "internal/version/version.go"}, "package version\n"},
+		{targets.GenerateFile{Language: "go", Path: "cmd/my-tool/version.go"}, "package mytool\n"},
+		{targets.GenerateFile{Language: "go", Path: "pkg/2fa/version.go"}, "package _2fa\n"},
+		{targets.GenerateFile{Language: "go", Path: "pkg/type/version.go"}, "package type_\n"},
+		{targets.GenerateFile{Language: "go", Path: "cmd/my-tool/version.go", Package: "main"}, "package main\n"},
+		{targets.GenerateFile{Language: "go"}, "\tVersion = \"v1.2.3\"\n\tCommit  = \"abc123\"\n"},
+		{targets.GenerateFile{Language: "ts"}, "export const VERSION = \"v1.2.3\";\n"},
+		{targets.GenerateFile{Language: "dart"}, "const String commit = 'abc123';\n"},
+	}
+	for _, test := range tests {
+		content, err := targets.RenderVersionFile(tag, "abc123", test.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(content, test.expected) {
+			t.Errorf("%+v: expected %q, got\n%s", test.file, test.expected, content)
+		}
+	}
+
+	_, err = targets.RenderVersionFile(tag, "abc123", targets.GenerateFile{Language: "cobol"})
+	if err == nil {
+		t.Error("an unknown language should fail")
+	}
+}
+
+func TestGenerateFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	err := os.WriteFile("version.tmpl", []byte("{{.Tag.Major}}.{{.Tag.Minor}} {{.Commit}}\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag, err := version.Parse("v2.5.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := []targets.GenerateFile{{Language: "rust"}, {Template: "version.tmpl", Path: "VERSION"}}
+	messages := make([]string, 0)
+	err = targets.GenerateFiles(tag, "abc123", files, func(message string) {
+		messages = append(messages, message)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(messages, []string{"Generated src/version.rs", "Generated VERSION"}) {
+		t.Errorf("unexpected messages %q", messages)
+	}
+
+	rust, err := os.ReadFile("src/version.rs")
+	if err != nil || !strings.Contains(string(rust), `pub const VERSION: &str = "v2.5.0";`) {
+		t.Errorf("unexpected src/version.rs: %s, %v", rust, err)
+	}
+	custom, err := os.ReadFile("VERSION")
+	if err != nil || string(custom) != "2.5 abc123\n" {
+		t.Errorf("unexpected VERSION: %q, %v", custom, err)
+	}
+
+	if err := targets.GenerateFiles(tag, "abc123", nil, nil); err == nil {
+		t.Error("generating without files should fail")
+	}
+}
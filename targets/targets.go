@@ -0,0 +1,258 @@
+// Package targets reads and writes the versions of the version files (package.json, pubspec.yaml, Cargo.toml,
+// Chart.yaml, .NET and Xcode projects), custom targets replacing versions in arbitrary files
+// and generated version source files.
+// The files are written to the current directory, the readers read them from any file system
+// (e.g. os.DirFS(".") or the files of another commit).
+package targets
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/MatthiasSchild/tagger/version"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// CustomTarget describes a custom write target, which replaces versions in arbitrary files
+type CustomTarget struct {
+	Files []FileReplacement `yaml:"files"`
+}
+
+// FileReplacement describes the files matched by a glob and the replacement done within them.
+// The pattern is a regular expression, the replacement a template over the Tag fields
+// (e.g. {{.Major}}.{{.Minor}}.{{.Patch}} or {{.String}}).
+type FileReplacement struct {
+	Glob    string `yaml:"glob"`
+	Pattern string `yaml:"pattern"`
+	Replace string `yaml:"replace"`
+}
+
+// Readers contains the functions reading the version of the built-in targets from a file system.
+// The version of helm is the appVersion, since the chart version is bumped independently of the tags.
+var Readers = map[string]func(fsys fs.FS) (version.Tag, error){
+	"npm": ReadPackageJson,
+	"flutter": func(fsys fs.FS) (version.Tag, error) {
+		tag, _, err := ReadPubspecYaml(fsys)
+		return tag, err
+	},
+	"cargo":  ReadCargoToml,
+	"helm":   ReadChartAppVersion,
+	"dotnet": ReadDotnetProject,
+	"xcode":  ReadXcodeProject,
+}
+
+// BuiltIn contains the names of the built-in targets, which cannot be used by custom targets
+var BuiltIn = []string{"npm", "flutter", "flutter+", "cargo", "helm", "dotnet", "xcode", "generate"}
+
+// Detect returns the built-in targets, whose version files exist in the file system.
+// .NET and Xcode projects are only detected with a readable version, since their files
+// often get the version elsewhere (e.g. an Info.plist referencing a build setting).
+func Detect(fsys fs.FS) []string {
+	result := make([]string, 0)
+
+	for _, target := range []string{"npm", "flutter", "cargo", "helm"} {
+		if _, err := fs.Stat(fsys, ManifestPaths[target]); err == nil {
+			result = append(result, target)
+		}
+	}
+
+	if _, err := ReadDotnetProject(fsys); err == nil {
+		result = append(result, "dotnet")
+	}
+	if _, err := ReadXcodeProject(fsys); err == nil {
+		result = append(result, "xcode")
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+// Build numbers of .NET and Xcode projects, which are derived from the highest existing one
+const (
+	IncrementBuildNumber = -1
+	KeepBuildNumber      = -2
+)
+
+// Options contain the settings of the targets, which are not part of the version files
+type Options struct {
+	// ChartBump is the part of the chart version to bump (major, minor, patch, by default patch),
+	// "tag" sets the chart version to the tag and "keep" keeps it
+	ChartBump string
+	// AppVersion sets the appVersion of the Chart.yaml to the tag as well
+	AppVersion bool
+	// BuildNumber is the revision or build number of .NET and Xcode projects,
+	// or IncrementBuildNumber or KeepBuildNumber to derive it from the existing ones
+	BuildNumber int
+	// Commit is the hash of the commit the release is based on, used by the generated files
+	Commit string
+	// Generate contains the version source files of the generate target
+	Generate []GenerateFile
+	// Custom contains the custom targets by name
+	Custom map[string]CustomTarget
+	// Report is called with a message for every file written by generate or a custom target,
+	// nil writes them silently
+	Report func(message string)
+}
+
+// Write writes the version into the files of a built-in or custom target
+func Write(target string, tag version.Tag, options Options) error {
+	var err error
+
+	switch target {
+	case "npm":
+		err = WritePackageJson(tag)
+		if err != nil {
+			return fmt.Errorf("failed to write package.json: %v", err)
+		}
+	case "flutter":
+		err = WritePubspecYaml(tag, false)
+		if err != nil {
+			return fmt.Errorf("failed to write pubspec.yaml: %v", err)
+		}
+	case "flutter+":
+		err = WritePubspecYaml(tag, true)
+		if err != nil {
+			return fmt.Errorf("failed to write pubspec.yaml: %v", err)
+		}
+	case "cargo":
+		err = WriteCargoToml(tag)
+		if err != nil {
+			return fmt.Errorf("failed to write Cargo.toml: %v", err)
+		}
+	case "helm":
+		chartVersion := tag
+		if options.ChartBump != "tag" {
+			chartVersion, err = ReadChartYaml(os.DirFS("."))
+			if err != nil {
+				return fmt.Errorf("failed to read Chart.yaml: %v", err)
+			}
+			switch options.ChartBump {
+			case "keep":
+			case "":
+				chartVersion = chartVersion.Bump("patch")
+			default:
+				chartVersion = chartVersion.Bump(options.ChartBump)
+			}
+		}
+		appVersion := ""
+		if options.AppVersion {
+			// The appVersion is the version of the app, so prereleases and builds are kept (e.g. 1.2.3-rc1)
+			appVersion = tag.Version()
+			if tag.MinusAddition != "" {
+				appVersion += "-" + tag.MinusAddition
+			}
+			if tag.PlusAddition != "" {
+				appVersion += "+" + tag.PlusAddition
+			}
+		}
+		err = WriteChartYaml(chartVersion, appVersion)
+		if err != nil {
+			return fmt.Errorf("failed to write Chart.yaml: %v", err)
+		}
+	case "dotnet":
+		err = WriteDotnetProject(tag, options.BuildNumber)
+		if err != nil {
+			return fmt.Errorf("failed to write project files: %v", err)
+		}
+	case "xcode":
+		err = WriteXcodeProject(tag, options.BuildNumber)
+		if err != nil {
+			return fmt.Errorf("failed to write Xcode project: %v", err)
+		}
+	case "generate":
+		err = GenerateFiles(tag, options.Commit, options.Generate, options.Report)
+		if err != nil {
+			return fmt.Errorf("failed to generate version files: %v", err)
+		}
+	default:
+		customTarget, ok := options.Custom[target]
+		if !ok {
+			return fmt.Errorf("unknown write option: %s", target)
+		}
+		err = WriteCustomTarget(tag, customTarget, options.Report)
+		if err != nil {
+			return fmt.Errorf("failed to write target %s: %v", target, err)
+		}
+	}
+
+	return nil
+}
+
+// Manifest describes a version file found in a file system.
+// Generate is the suggested version source file of the generate target.
+type Manifest struct {
+	Path     string
+	Target   string
+	Version  string
+	Generate *GenerateFile
+}
+
+// ManifestPaths contains the (displayed) path of the version files of the built-in targets
+var ManifestPaths = map[string]string{
+	"npm":     "package.json",
+	"flutter": "pubspec.yaml",
+	"cargo":   "Cargo.toml",
+	"helm":    "Chart.yaml",
+	"dotnet":  "*.csproj",
+	"xcode":   "*.xcodeproj",
+}
+
+// SuggestedTargets contains suggested custom targets for manifests without a built-in target
+var SuggestedTargets = map[string]CustomTarget{
+	"python": {Files: []FileReplacement{{
+		Glob:    "pyproject.toml",
+		Pattern: `(?m)^(version\s*=\s*")[^"]*(")`,
+		Replace: "${1}{{.Version}}${2}",
+	}}},
+	"version-file": {Files: []FileReplacement{{
+		Glob:    "VERSION",
+		Pattern: `(?m)^v?[0-9]+\.[0-9]+\.[0-9]+.*$`,
+		Replace: "{{.Version}}",
+	}}},
+}
+
+// DetectManifests scans the file system for the version files of the built-in targets
+// and other known manifests, which can be handled by custom targets or generated files.
+// The version stays empty, when it could not be read.
+func DetectManifests(fsys fs.FS) []Manifest {
+	result := make([]Manifest, 0)
+
+	for _, target := range Detect(fsys) {
+		manifest := Manifest{Path: ManifestPaths[target], Target: target}
+		if version, err := Readers[target](fsys); err == nil {
+			manifest.Version = version.Version()
+		}
+		result = append(result, manifest)
+	}
+
+	// Go modules are versioned by their tags only, so the version can just be generated into code
+	if file, err := goGenerateFile(fsys); err == nil {
+		result = append(result, Manifest{Path: "go.mod", Target: "generate", Generate: &file})
+	}
+
+	if content, err := fs.ReadFile(fsys, "pyproject.toml"); err == nil {
+		projectData := &struct {
+			Project struct {
+				Version string `toml:"version"`
+			} `toml:"project"`
+		}{}
+		manifest := Manifest{Path: "pyproject.toml", Target: "python"}
+		if toml.Unmarshal(content, projectData) == nil {
+			manifest.Version = projectData.Project.Version
+		}
+		result = append(result, manifest)
+	}
+
+	if content, err := fs.ReadFile(fsys, "VERSION"); err == nil {
+		result = append(result, Manifest{
+			Path:    "VERSION",
+			Target:  "version-file",
+			Version: strings.TrimPrefix(strings.TrimSpace(string(content)), "v"),
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,310 @@
+package targets_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/MatthiasSchild/tagger/targets"
+	"github.com/MatthiasSchild/tagger/version"
+)
+
+func TestReaders(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json":            {Data: []byte(`{"name": "app", "version": "1.2.3"}`)},
+		"pubspec.yaml":            {Data: []byte("name: app\nversion: 1.2.3+7\n")},
+		"Cargo.toml":              {Data: []byte("[package]\nname = \"app\"\nversion = \"1.2.3\"\n\n[dependencies]\nserde = { version = \"1.0.0\" }\n")},
+		"Chart.yaml":              {Data: []byte("apiVersion: v2\nname: app\nversion: 0.4.1\nappVersion: v1.2.3\n")},
+		"Directory.Build.props":   {Data: []byte("<Project><PropertyGroup><Version>1.2.3</Version></PropertyGroup></Project>")},
+		"src/App/App.csproj":      {Data: []byte("<Project><PropertyGroup><VersionPrefix>1.2.3</VersionPrefix></PropertyGroup></Project>")},
+		"src/App/bin/Old.csproj":  {Data: []byte("<Project><PropertyGroup><Version>0.0.1</Version></PropertyGroup></Project>")},
+		"node_modules/x/a.csproj": {Data: []byte("<Project><PropertyGroup><Version>0.0.2</Version></PropertyGroup></Project>")},
+	}
+
+	for _, target := range []string{"npm", "flutter", "cargo", "helm", "dotnet"} {
+		tag, err := targets.Readers[target](fsys)
+		if err != nil {
+			t.Errorf("%s: %v", target, err)
+			continue
+		}
+		if tag.String() != "v1.2.3" {
+			t.Errorf("%s: expected v1.2.3 without addition, got %s", target, tag)
+		}
+	}
+
+	_, build, err := targets.ReadPubspecYaml(fsys)
+	if err != nil || build != 7 {
+		t.Errorf("expected the build number 7, got %d, %v", build, err)
+	}
+
+	if detected := targets.Detect(fsys); !slices.Equal(detected, []string{"cargo", "dotnet", "flutter", "helm", "npm"}) {
+		t.Errorf("unexpected detected targets %v", detected)
+	}
+}
+
+func TestReadPubspecYamlWithoutBuildNumber(t *testing.T) {
+	fsys := fstest.MapFS{"pubspec.yaml": {Data: []byte("name: app\nversion: 1.2.3\n")}}
+
+	tag, build, err := targets.ReadPubspecYaml(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag.String() != "v1.2.3" || build != 0 {
+		t.Errorf("expected v1.2.3 with build number 0, got %s and %d", tag, build)
+	}
+}
+
+func TestReadersRejectInvalidVersions(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json":          {Data: []byte(`{"version": "latest"}`)},
+		"Directory.Build.props": {Data: []byte("<Project><PropertyGroup><Version>1.2.3</Version></PropertyGroup></Project>")},
+		"App.csproj":            {Data: []byte("<Project><PropertyGroup><Version>1.3.0</Version></PropertyGroup></Project>")},
+	}
+
+	if _, err := targets.ReadPackageJson(fsys); err == nil {
+		t.Error("a package.json without version should fail")
+	}
+	if _, err := targets.ReadDotnetProject(fsys); err == nil {
+		t.Error("differing project versions should fail")
+	}
+	if _, err := targets.ReadCargoToml(fsys); err == nil {
+		t.Error("a missing Cargo.toml should fail")
+	}
+}
+
+func TestDetectManifests(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json":   {Data: []byte(`{"version": "2.0.0"}`)},
+		"go.mod":         {Data: []byte("module example.com/app\n")},
+		"pyproject.toml": {Data: []byte("[project]\nname = \"app\"\nversion = \"2.0.1\"\n")},
+		"VERSION":        {Data: []byte("v2.0.2\n")},
+	}
+
+	result := make([]string, 0)
+	for _, manifest := range targets.DetectManifests(fsys) {
+		result = append(result, manifest.Path+" "+manifest.Target+" "+manifest.Version)
+	}
+	expected := []string{"package.json npm 2.0.0", "go.mod generate ", "pyproject.toml python 2.0.1", "VERSION version-file 2.0.2"}
+	if !slices.Equal(result, expected) {
+		t.Errorf("unexpected manifests %q", result)
+	}
+}
+
+func TestReadXcodeProject(t *testing.T) {
+	tag, err := targets.ReadXcodeProject(os.DirFS("testdata/xcode/app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag.String() != "v1.0.0" {
+		t.Errorf("expected the short version 1.0 as v1.0.0, got %s", tag)
+	}
+
+	// Flutter projects reference the version of the pubspec.yaml only
+	_, err = targets.ReadXcodeProject(os.DirFS("testdata/xcode/flutter"))
+	if err == nil || !strings.Contains(err.Error(), "no MARKETING_VERSION") {
+		t.Errorf("expected no concrete version in the Flutter project, got %v", err)
+	}
+	if detected := targets.Detect(os.DirFS("testdata/xcode/flutter")); slices.Contains(detected, "xcode") {
+		t.Errorf("expected the Flutter project not to be detected as xcode, got %v", detected)
+	}
+	if detected := targets.Detect(os.DirFS("testdata/xcode/app")); !slices.Equal(detected, []string{"xcode"}) {
+		t.Errorf("expected the app to be detected as xcode, got %v", detected)
+	}
+
+	fsys := fstest.MapFS{
+		"App.xcodeproj/project.pbxproj": {Data: []byte("MARKETING_VERSION = 1.0;\nMARKETING_VERSION = \"1.0.0\";\n")},
+		"App/Info.plist":                {Data: []byte("<key>CFBundleShortVersionString</key>\n<string>1.1</string>")},
+	}
+	_, err = targets.ReadXcodeProject(fsys)
+	if err == nil || !strings.Contains(err.Error(), "differ: 1.0, 1.1") {
+		t.Errorf("expected differing versions to fail, got %v", err)
+	}
+}
+
+func TestWriteBuildNumber(t *testing.T) {
+	files := map[string]string{
+		"App.csproj":                    "<Project><PropertyGroup><Version>1.0.0</Version><FileVersion>1.0.0.4</FileVersion></PropertyGroup></Project>",
+		"App.xcodeproj/project.pbxproj": "MARKETING_VERSION = 1.0.0;\nCURRENT_PROJECT_VERSION = 7;\n",
+	}
+	tests := []struct {
+		buildNumber int
+		fileVersion string
+		build       string
+	}{
+		{targets.IncrementBuildNumber, "1.1.0.5", "CURRENT_PROJECT_VERSION = 8;"},
+		{targets.KeepBuildNumber, "1.1.0.4", "CURRENT_PROJECT_VERSION = 7;"},
+		{12, "1.1.0.12", "CURRENT_PROJECT_VERSION = 12;"},
+	}
+
+	for _, test := range tests {
+		dir := t.TempDir()
+		t.Chdir(dir)
+		for path, content := range files {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		tag, err := version.Parse("v1.1.0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		options := targets.Options{BuildNumber: test.buildNumber}
+		for _, target := range []string{"dotnet", "xcode"} {
+			if err := targets.Write(target, tag, options); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		project, _ := os.ReadFile("App.csproj")
+		if !strings.Contains(string(project), "<FileVersion>"+test.fileVersion+"</FileVersion>") {
+			t.Errorf("build number %d: expected file version %s, got %s", test.buildNumber, test.fileVersion, project)
+		}
+		pbxproj, _ := os.ReadFile("App.xcodeproj/project.pbxproj")
+		if !strings.Contains(string(pbxproj), test.build) {
+			t.Errorf("build number %d: expected %s, got %s", test.buildNumber, test.build, pbxproj)
+		}
+	}
+}
+
+func TestWriteChartYaml(t *testing.T) {
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"Chart.yaml": "apiVersion: v2\nname: app\nversion: 0.4.1 # chart\nappVersion: \"v1.0.0\"\n" +
+			"dependencies:\n  - name: sub\n    version: 0.1.0\n    repository: file://charts/sub\n" +
+			"  - name: redis\n    version: 17.0.0\n    repository: https://charts.bitnami.com/bitnami\n",
+		"Chart.lock": "dependencies:\n- name: sub\n  repository: file://charts/sub\n  version: 0.1.0\n" +
+			"- name: redis\n  repository: https://charts.bitnami.com/bitnami\n  version: 17.0.0\n" +
+			"digest: sha256:0000\ngenerated: \"2024-01-01T00:00:00Z\"\n",
+		"charts/sub/Chart.yaml": "apiVersion: v2\nname: sub\nversion: 0.2.0\n",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tag, err := version.Parse("v1.2.0-rc1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = targets.Write("helm", tag, targets.Options{AppVersion: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chart, _ := os.ReadFile("Chart.yaml")
+	for _, expected := range []string{"version: 0.4.2 # chart\n", "appVersion: \"v1.2.0-rc1\"\n", "    version: 0.2.0\n", "    version: 17.0.0\n"} {
+		if !strings.Contains(string(chart), expected) {
+			t.Errorf("expected %q in the Chart.yaml, got\n%s", expected, chart)
+		}
+	}
+
+	// helm hashes the JSON encoded dependencies of the Chart.yaml and the Chart.lock
+	dependencies := `[{"name":"sub","version":"0.2.0","repository":"file://charts/sub"},` +
+		`{"name":"redis","version":"17.0.0","repository":"https://charts.bitnami.com/bitnami"}]`
+	sum := sha256.Sum256([]byte("[" + dependencies + "," + dependencies + "]"))
+	lock, _ := os.ReadFile("Chart.lock")
+	for _, expected := range []string{"  version: 0.2.0\n", "  version: 17.0.0\n", "digest: sha256:" + hex.EncodeToString(sum[:]) + "\n"} {
+		if !strings.Contains(string(lock), expected) {
+			t.Errorf("expected %q in the Chart.lock, got\n%s", expected, lock)
+		}
+	}
+}
+
+func TestWriteChartYamlBump(t *testing.T) {
+	tests := []struct {
+		bump     string
+		expected string
+	}{
+		{"", "version: 0.4.2\n"},
+		{"minor", "version: 0.5.0\n"},
+		{"tag", "version: 1.2.0\n"},
+		{"keep", "version: 0.4.1\n"},
+	}
+	for _, test := range tests {
+		t.Chdir(t.TempDir())
+		if err := os.WriteFile("Chart.yaml", []byte("name: app\nversion: 0.4.1\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		tag, err := version.Parse("v1.2.0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = targets.Write("helm", tag, targets.Options{ChartBump: test.bump})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		chart, _ := os.ReadFile("Chart.yaml")
+		if !strings.Contains(string(chart), test.expected) {
+			t.Errorf("chart bump %q: expected %q, got\n%s", test.bump, test.expected, chart)
+		}
+	}
+}
+
+func TestWriteCustomTarget(t *testing.T) {
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"README.md":  "Install tagger@v1.0.0 or tagger@v1.0.0-rc1\n",
+		"Dockerfile": "FROM alpine\nARG VERSION=1.0.0\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tag, err := version.Parse("v1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := targets.CustomTarget{Files: []targets.FileReplacement{
+		{Glob: "README.md", Pattern: `tagger@v[0-9.]+(-\w+)?`, Replace: "tagger@{{.String}}"},
+		{Glob: "Docker*", Pattern: `(ARG VERSION=).*`, Replace: "${1}{{.Major}}.{{.Minor}}.{{.Patch}}"},
+	}}
+	messages := make([]string, 0)
+	report := func(message string) {
+		messages = append(messages, message)
+	}
+	err = targets.Write("release", tag, targets.Options{Custom: map[string]targets.CustomTarget{"release": target}, Report: report})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(messages, []string{"README.md: 2 replacement(s)", "Dockerfile: 1 replacement(s)"}) {
+		t.Errorf("unexpected messages %q", messages)
+	}
+
+	readme, _ := os.ReadFile("README.md")
+	if string(readme) != "Install tagger@v1.1.0 or tagger@v1.1.0\n" {
+		t.Errorf("unexpected README.md %q", readme)
+	}
+	dockerfile, _ := os.ReadFile("Dockerfile")
+	if string(dockerfile) != "FROM alpine\nARG VERSION=1.1.0\n" {
+		t.Errorf("unexpected Dockerfile %q", dockerfile)
+	}
+
+	// Nothing is written, when a file does not contain the pattern
+	target.Files = append(target.Files, targets.FileReplacement{Glob: "*.md", Pattern: "version: .*", Replace: "version: {{.String}}"})
+	if err := targets.WriteCustomTarget(tag, target, nil); err == nil || !strings.Contains(err.Error(), "did not match") {
+		t.Errorf("expected the missing pattern to fail, got %v", err)
+	}
+	if err := targets.WriteCustomTarget(tag, targets.CustomTarget{Files: []targets.FileReplacement{{Glob: "*.txt", Pattern: "x"}}}, nil); err == nil {
+		t.Error("expected a glob without files to fail")
+	}
+	if err := targets.Write("unknown", tag, targets.Options{}); err == nil {
+		t.Error("expected an unknown target to fail")
+	}
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// versionRequirementPattern matches a version requirement with an optional operator (e.g. ^1.2.3).
+// The groups are: operator and version.
+const versionRequirementPattern = `(\^|~|>=|=)?(\d+\.\d+\.\d+[^"]*)`
+
+var goModRequireBlockRegex = regexp.MustCompile(`^\s*require\s*\(\s*$`)
+
+// UpdateJsonDependencyVersion replaces the version requirement of a dependency in a package.json.
+// The operator of the requirement (e.g. ^ or ~) is kept.
+// Requirements without a version (e.g. workspace:* or file:../lib) are kept untouched.
+func UpdateJsonDependencyVersion(content string, name string, version string) string {
+	re := regexp.MustCompile(`("` + regexp.QuoteMeta(name) + `"\s*:\s*")` + versionRequirementPattern + `(")`)
+	return re.ReplaceAllString(content, "${1}${2}"+version+"${4}")
+}
+
+// UpdateCargoDependencyVersion replaces the version requirement of a dependency in a Cargo.toml.
+// Plain (name = "1.2.3"), inline tables (name = { path = "..", version = "1.2.3" })
+// and dependency tables ([dependencies.name]) are supported.
+func UpdateCargoDependencyVersion(content string, name string, version string) string {
+	quotedName := regexp.QuoteMeta(name)
+	plainRegex := regexp.MustCompile(`^(\s*` + quotedName + `\s*=\s*")` + versionRequirementPattern + `(".*)$`)
+	inlineRegex := regexp.MustCompile(`^(\s*` + quotedName + `\s*=\s*\{.*\bversion\s*=\s*")` + versionRequirementPattern + `(".*)$`)
+	tableVersionRegex := regexp.MustCompile(`^(\s*version\s*=\s*")` + versionRequirementPattern + `(".*)$`)
+
+	result := make([]string, 0)
+	withinDependencies := false
+	withinDependencyTable := false
+
+	for _, line := range strings.Split(content, "\n") {
+		groupMatch := tomlGroupRegex.FindStringSubmatch(line)
+		if len(groupMatch) > 0 {
+			group := strings.Trim(groupMatch[1], "[]")
+			withinDependencies = strings.HasSuffix(group, "dependencies")
+			withinDependencyTable = strings.HasSuffix(group, "dependencies."+name)
+		} else if withinDependencies {
+			if parts := inlineRegex.FindStringSubmatch(line); len(parts) > 0 {
+				line = parts[1] + parts[2] + version + parts[4]
+			} else if parts := plainRegex.FindStringSubmatch(line); len(parts) > 0 {
+				line = parts[1] + parts[2] + version + parts[4]
+			}
+		} else if withinDependencyTable {
+			if parts := tableVersionRegex.FindStringSubmatch(line); len(parts) > 0 {
+				line = parts[1] + parts[2] + version + parts[4]
+			}
+		}
+
+		result = append(result, line)
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// UpdateGoModRequire replaces the required version of a module in a go.mod.
+// Single line requirements and require blocks are supported.
+func UpdateGoModRequire(content string, module string, version string) string {
+	quotedModule := regexp.QuoteMeta(module)
+	singleRegex := regexp.MustCompile(`^(\s*require\s+` + quotedModule + `\s+)(\S+)(.*)$`)
+	blockRegex := regexp.MustCompile(`^(\s*` + quotedModule + `\s+)(v\S+)(.*)$`)
+
+	result := make([]string, 0)
+	withinRequire := false
+
+	for _, line := range strings.Split(content, "\n") {
+		if goModRequireBlockRegex.MatchString(line) {
+			withinRequire = true
+		} else if withinRequire && strings.TrimSpace(line) == ")" {
+			withinRequire = false
+		} else if parts := singleRegex.FindStringSubmatch(line); len(parts) > 0 {
+			line = parts[1] + version + parts[3]
+		} else if withinRequire {
+			if parts := blockRegex.FindStringSubmatch(line); len(parts) > 0 {
+				line = parts[1] + version + parts[3]
+			}
+		}
+
+		result = append(result, line)
+	}
+
+	return strings.Join(result, "\n")
+}
@@ -0,0 +1,109 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/MatthiasSchild/tagger/utils"
+)
+
+func TestUpdateJsonDependencyVersion(t *testing.T) {
+	input := `{
+  "name": "app",
+  "dependencies": {
+    "lib": "^1.2.3",
+    "other": "workspace:*"
+  },
+  "devDependencies": {
+    "lib-test": "~1.0.0"
+  }
+}`
+	expect := `{
+  "name": "app",
+  "dependencies": {
+    "lib": "^1.3.0",
+    "other": "workspace:*"
+  },
+  "devDependencies": {
+    "lib-test": "~1.0.0"
+  }
+}`
+
+	result := utils.UpdateJsonDependencyVersion(input, "lib", "1.3.0")
+	result = utils.UpdateJsonDependencyVersion(result, "other", "1.3.0")
+	if result != expect {
+		t.Errorf("result mismatches:\n%s\nexpect:\n%s", result, expect)
+	}
+}
+
+func TestUpdateCargoDependencyVersion(t *testing.T) {
+	input := `[package]
+name = "app"
+version = "0.1.0"
+
+[dependencies]
+lib = { path = "../lib", version = "1.2.3" }
+serde = "1.0.0"
+
+[dev-dependencies]
+lib = "=1.2.3"
+
+[build-dependencies.lib]
+path = "../lib"
+version = "1.2.3"
+`
+	expect := `[package]
+name = "app"
+version = "0.1.0"
+
+[dependencies]
+lib = { path = "../lib", version = "1.3.0" }
+serde = "1.0.0"
+
+[dev-dependencies]
+lib = "=1.3.0"
+
+[build-dependencies.lib]
+path = "../lib"
+version = "1.3.0"
+`
+
+	result := utils.UpdateCargoDependencyVersion(input, "lib", "1.3.0")
+	if result != expect {
+		t.Errorf("result mismatches:\n%s\nexpect:\n%s", result, expect)
+	}
+}
+
+func TestUpdateGoModRequire(t *testing.T) {
+	input := `module example.com/app
+
+go 1.24
+
+require example.com/lib v1.2.3
+
+require (
+	example.com/other v0.1.0
+	example.com/lib/v2 v2.0.0 // indirect
+)
+
+replace example.com/lib => ../lib
+`
+	expect := `module example.com/app
+
+go 1.24
+
+require example.com/lib v1.3.0
+
+require (
+	example.com/other v0.1.0
+	example.com/lib/v2 v2.1.0 // indirect
+)
+
+replace example.com/lib => ../lib
+`
+
+	result := utils.UpdateGoModRequire(input, "example.com/lib", "v1.3.0")
+	result = utils.UpdateGoModRequire(result, "example.com/lib/v2", "v2.1.0")
+	if result != expect {
+		t.Errorf("result mismatches:\n%s\nexpect:\n%s", result, expect)
+	}
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// AddGoModRetract appends a retract directive to a go.mod.
+// The versions are a single version (v1.2.3) or a closed interval ([v1.2.0, v1.2.5]),
+// the rationale is added as comment, which is shown by "go list -m -retracted".
+func AddGoModRetract(content string, versions string, rationale string) string {
+	line := "retract " + versions
+	if rationale != "" {
+		line += " // " + rationale
+	}
+
+	content = strings.TrimRight(content, "\n")
+	return content + "\n\n" + line + "\n"
+}
+
+// AddChangelogRetraction adds the note as first item to the sections of the version headings
+// (e.g. "## v1.2.3 - 2024-10-03", "## 1.2.3" or "## [1.2.3]") in a markdown changelog.
+// The result is false, when the changelog has no heading of the version.
+func AddChangelogRetraction(content string, version string, note string) (string, bool) {
+	headingRegex := regexp.MustCompile(`^#+\s(?:.*[^\d.])?v?` + regexp.QuoteMeta(version) + `([^\d.]|$)`)
+	lines := strings.Split(content, "\n")
+	result := make([]string, 0, len(lines)+1)
+	found := false
+
+	for index := 0; index < len(lines); index++ {
+		result = append(result, lines[index])
+		if !headingRegex.MatchString(lines[index]) {
+			continue
+		}
+
+		// The note is the first list item, separated from the heading by an empty line
+		found = true
+		if index+1 < len(lines) && lines[index+1] == "" {
+			index++
+		}
+		result = append(result, "", "- retracted: "+note)
+		if index+1 < len(lines) && lines[index+1] != "" && !strings.HasPrefix(lines[index+1], "-") {
+			result = append(result, "")
+		}
+	}
+
+	return strings.Join(result, "\n"), found
+}
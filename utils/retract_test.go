@@ -0,0 +1,68 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/MatthiasSchild/tagger/utils"
+)
+
+func TestAddGoModRetract(t *testing.T) {
+	input := "module example.com/app\n\ngo 1.24.0\n"
+	expect := "module example.com/app\n\ngo 1.24.0\n\nretract [v1.2.0, v1.2.5] // broken build\n"
+
+	result := utils.AddGoModRetract(input, "[v1.2.0, v1.2.5]", "broken build")
+	if result != expect {
+		t.Errorf("result mismatches:\n%s\nexpect:\n%s", result, expect)
+	}
+}
+
+func TestAddChangelogRetraction(t *testing.T) {
+	input := `# Changelog
+
+## v1.2.30 - 2024-10-04
+
+- fix: other fix
+
+## v1.2.3 - 2024-10-03
+
+- feat: broken feature
+`
+	expect := `# Changelog
+
+## v1.2.30 - 2024-10-04
+
+- fix: other fix
+
+## v1.2.3 - 2024-10-03
+
+- retracted: do not use this version
+- feat: broken feature
+`
+
+	result, found := utils.AddChangelogRetraction(input, "1.2.3", "do not use this version")
+	if !found || result != expect {
+		t.Errorf("result mismatches:\n%s\nexpect:\n%s", result, expect)
+	}
+
+	_, found = utils.AddChangelogRetraction(input, "1.2.4", "do not use this version")
+	if found {
+		t.Error("v1.2.4 has no heading")
+	}
+
+	headings := map[string]bool{
+		"## 1.2.3 - 2024-10-03":   true,
+		"# 1.2.3":                 true,
+		"## [1.2.3] - 2024-10-03": true,
+		"## v1.2.3":               true,
+		"## Release 1.2.3":        true,
+		"## 11.2.3":               false,
+		"## 1.2.30":               false,
+		"1.2.3":                   false,
+	}
+	for heading, expectFound := range headings {
+		_, found = utils.AddChangelogRetraction(heading+"\n\n- feat: feature\n", "1.2.3", "broken")
+		if found != expectFound {
+			t.Errorf("%q: expected found %v", heading, expectFound)
+		}
+	}
+}
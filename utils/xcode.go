@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pbxprojSettingRegex builds a regex matching a build setting in a project.pbxproj
+// (e.g. MARKETING_VERSION = 1.2.3;).
+// The groups are: prefix, opening quote, value and the closing quote with semicolon.
+func pbxprojSettingRegex(setting string) *regexp.Regexp {
+	return regexp.MustCompile(`(\b` + regexp.QuoteMeta(setting) + `\s*=\s*)("?)([^";\n]*)("?\s*;)`)
+}
+
+// plistStringRegex builds a regex matching a string value of a key in a XML property list.
+// The groups are: the key with the opening tag, the value and the closing tag.
+func plistStringRegex(key string) *regexp.Regexp {
+	return regexp.MustCompile(`(<key>` + regexp.QuoteMeta(key) + `</key>\s*<string>)([^<]*)(</string>)`)
+}
+
+// IsBuildSettingReference checks, if the value references other build settings
+// (e.g. $(inherited) or ${FLUTTER_BUILD_NAME}), so it is no concrete value
+func IsBuildSettingReference(value string) bool {
+	return strings.Contains(value, "$(") || strings.Contains(value, "${")
+}
+
+// UpdatePbxprojSetting replaces the value of a build setting in all build configurations.
+// Values referencing other settings (e.g. $(inherited)) are kept.
+func UpdatePbxprojSetting(content string, setting string, value string) string {
+	re := pbxprojSettingRegex(setting)
+	return re.ReplaceAllStringFunc(content, func(match string) string {
+		parts := re.FindStringSubmatch(match)
+		if IsBuildSettingReference(parts[3]) {
+			return match
+		}
+		return parts[1] + parts[2] + value + parts[4]
+	})
+}
+
+// ReadPbxprojSettings returns the values of a build setting from all build configurations.
+func ReadPbxprojSettings(content string, setting string) []string {
+	result := make([]string, 0)
+	for _, parts := range pbxprojSettingRegex(setting).FindAllStringSubmatch(content, -1) {
+		result = append(result, parts[3])
+	}
+	return result
+}
+
+// UpdatePlistString replaces the string value of a key in a XML property list.
+// Values referencing build settings (e.g. $(MARKETING_VERSION)) are kept.
+func UpdatePlistString(content string, key string, value string) string {
+	re := plistStringRegex(key)
+	return re.ReplaceAllStringFunc(content, func(match string) string {
+		parts := re.FindStringSubmatch(match)
+		if IsBuildSettingReference(parts[2]) {
+			return match
+		}
+		return parts[1] + value + parts[3]
+	})
+}
+
+// ReadPlistString returns the string value of a key in a XML property list
+// or an empty string, when the key does not exist.
+func ReadPlistString(content string, key string) string {
+	parts := plistStringRegex(key).FindStringSubmatch(content)
+	if parts == nil {
+		return ""
+	}
+	return parts[2]
+}
+
+// MaxBuildNumber returns the highest numeric value of the given build numbers.
+// Values, which are not numeric, are ignored.
+func MaxBuildNumber(values []string) int {
+	result := 0
+	for _, value := range values {
+		number, err := strconv.Atoi(value)
+		if err == nil && number > result {
+			result = number
+		}
+	}
+	return result
+}
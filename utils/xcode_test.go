@@ -0,0 +1,75 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/MatthiasSchild/tagger/utils"
+)
+
+const pbxprojInput = `		4A1B /* Debug */ = {
+			buildSettings = {
+				CURRENT_PROJECT_VERSION = 7;
+				MARKETING_VERSION = 1.0;
+			};
+		};
+		4A1C /* Release */ = {
+			buildSettings = {
+				CURRENT_PROJECT_VERSION = "12";
+				MARKETING_VERSION = "$(inherited)";
+			};
+		};
+`
+
+const pbxprojExpected = `		4A1B /* Debug */ = {
+			buildSettings = {
+				CURRENT_PROJECT_VERSION = 13;
+				MARKETING_VERSION = 1.1.0;
+			};
+		};
+		4A1C /* Release */ = {
+			buildSettings = {
+				CURRENT_PROJECT_VERSION = "13";
+				MARKETING_VERSION = "$(inherited)";
+			};
+		};
+`
+
+const plistInput = `<dict>
+	<key>CFBundleShortVersionString</key>
+	<string>$(MARKETING_VERSION)</string>
+	<key>CFBundleVersion</key>
+	<string>12</string>
+</dict>`
+
+const plistExpected = `<dict>
+	<key>CFBundleShortVersionString</key>
+	<string>$(MARKETING_VERSION)</string>
+	<key>CFBundleVersion</key>
+	<string>13</string>
+</dict>`
+
+func TestUpdatePbxprojSetting(t *testing.T) {
+	buildNumber := utils.MaxBuildNumber(utils.ReadPbxprojSettings(pbxprojInput, "CURRENT_PROJECT_VERSION"))
+	if buildNumber != 12 {
+		t.Errorf("build number mismatches, result=%d, expect=12", buildNumber)
+		return
+	}
+
+	result := utils.UpdatePbxprojSetting(pbxprojInput, "CURRENT_PROJECT_VERSION", "13")
+	result = utils.UpdatePbxprojSetting(result, "MARKETING_VERSION", "1.1.0")
+	if result != pbxprojExpected {
+		t.Errorf("result mismatches:\n%s\nexpect:\n%s", result, pbxprojExpected)
+	}
+}
+
+func TestUpdatePlistString(t *testing.T) {
+	result := utils.UpdatePlistString(plistInput, "CFBundleShortVersionString", "1.1.0")
+	result = utils.UpdatePlistString(result, "CFBundleVersion", "13")
+	if result != plistExpected {
+		t.Errorf("result mismatches:\n%s\nexpect:\n%s", result, plistExpected)
+	}
+
+	if version := utils.ReadPlistString(plistInput, "CFBundleVersion"); version != "12" {
+		t.Errorf("read CFBundleVersion mismatches, result=%s, expect=12", version)
+	}
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"regexp"
+)
+
+// xmlElementRegex builds a regex matching a simple element with text content (e.g. <Version>1.2.3</Version>).
+// The groups are: opening tag, content and closing tag.
+func xmlElementRegex(element string) *regexp.Regexp {
+	name := regexp.QuoteMeta(element)
+	return regexp.MustCompile(`(<` + name + `(?:\s[^>]*)?>)([^<]*)(</` + name + `>)`)
+}
+
+// UpdateXmlElement replaces the content of all elements with the given name.
+// Attributes of the element (e.g. a Condition) are kept.
+func UpdateXmlElement(content string, element string, value string) string {
+	re := xmlElementRegex(element)
+	return re.ReplaceAllStringFunc(content, func(match string) string {
+		parts := re.FindStringSubmatch(match)
+		return parts[1] + value + parts[3]
+	})
+}
+
+// ReadXmlElement returns the content of the first element with the given name
+// or an empty string, when the element does not exist.
+func ReadXmlElement(content string, element string) string {
+	parts := xmlElementRegex(element).FindStringSubmatch(content)
+	if parts == nil {
+		return ""
+	}
+	return parts[2]
+}
@@ -0,0 +1,60 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/MatthiasSchild/tagger/utils"
+)
+
+const csprojInput = `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    <VersionPrefix>1.0.0</VersionPrefix>
+    <Version>1.0.0</Version>
+    <FileVersion>1.0.0.4</FileVersion>
+  </PropertyGroup>
+  <PropertyGroup Condition="'$(Configuration)' == 'Release'">
+    <Version Condition="'$(CI)' == 'true'">1.0.0</Version>
+    <VersionSuffix></VersionSuffix>
+  </PropertyGroup>
+</Project>
+`
+
+const csprojExpected = `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    <VersionPrefix>1.0.0</VersionPrefix>
+    <Version>1.1.0-rc1</Version>
+    <FileVersion>1.0.0.4</FileVersion>
+  </PropertyGroup>
+  <PropertyGroup Condition="'$(Configuration)' == 'Release'">
+    <Version Condition="'$(CI)' == 'true'">1.1.0-rc1</Version>
+    <VersionSuffix>rc1</VersionSuffix>
+  </PropertyGroup>
+</Project>
+`
+
+func TestUpdateXmlElement(t *testing.T) {
+	result := utils.UpdateXmlElement(csprojInput, "Version", "1.1.0-rc1")
+	result = utils.UpdateXmlElement(result, "VersionSuffix", "rc1")
+	result = utils.UpdateXmlElement(result, "AssemblyVersion", "1.1.0.0")
+
+	if result != csprojExpected {
+		t.Errorf("unexpected result:\n%s", result)
+	}
+}
+
+func TestReadXmlElement(t *testing.T) {
+	tests := map[string]string{
+		"Version":         "1.0.0",
+		"VersionPrefix":   "1.0.0",
+		"FileVersion":     "1.0.0.4",
+		"VersionSuffix":   "",
+		"AssemblyVersion": "",
+	}
+	for element, expected := range tests {
+		if value := utils.ReadXmlElement(csprojInput, element); value != expected {
+			t.Errorf("%s: expected %q, got %q", element, expected, value)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+var yamlTopLevelRegex = regexp.MustCompile(`^[^\s#-]`)
+var yamlListItemRegex = regexp.MustCompile(`^(\s*)-\s`)
+var yamlNameRegex = regexp.MustCompile(`^\s*(?:-\s+)?name:\s*["']?([^"'#\s]+)`)
+
+// yamlValueRegex builds a regex matching a line with the given key and a scalar value.
+// The groups are: prefix, opening quote, value, closing quote and the rest of the line.
+func yamlValueRegex(key string, topLevel bool) *regexp.Regexp {
+	indent := `\s*(?:-\s+)?`
+	if topLevel {
+		indent = ``
+	}
+	return regexp.MustCompile(`^(` + indent + regexp.QuoteMeta(key) + `:\s*)(["']?)([^"'#\s]*)(["']?)(.*)$`)
+}
+
+// UpdateYamlValue replaces the value of a top level key of a yaml document.
+// Quotes and comments of the line are kept.
+func UpdateYamlValue(content string, key string, value string) string {
+	re := yamlValueRegex(key, true)
+	lines := strings.Split(content, "\n")
+
+	for index, line := range lines {
+		lineParts := re.FindStringSubmatch(line)
+		if len(lineParts) != 0 {
+			lines[index] = lineParts[1] + lineParts[2] + value + lineParts[4] + lineParts[5]
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// ReadYamlValue returns the value of a top level key of a yaml document
+// or an empty string, when the key does not exist.
+func ReadYamlValue(content string, key string) string {
+	re := yamlValueRegex(key, true)
+
+	for _, line := range strings.Split(content, "\n") {
+		lineParts := re.FindStringSubmatch(line)
+		if len(lineParts) != 0 {
+			return lineParts[3]
+		}
+	}
+
+	return ""
+}
+
+// UpdateYamlDependencyVersions replaces the versions of the entries within the top level
+// "dependencies" list (e.g. in a Chart.yaml or Chart.lock).
+// The versions map contains the new version by the name of the dependency.
+// Dependencies, which are not part of the map, are kept untouched.
+func UpdateYamlDependencyVersions(content string, versions map[string]string) string {
+	versionRegex := yamlValueRegex("version", false)
+	lines := strings.Split(content, "\n")
+	withinDependencies := false
+	itemIndent := ""
+	itemStart := -1
+
+	updateItem := func(end int) {
+		if itemStart < 0 {
+			return
+		}
+
+		name := ""
+		versionLine := -1
+		for index := itemStart; index < end; index++ {
+			if nameMatch := yamlNameRegex.FindStringSubmatch(lines[index]); len(nameMatch) > 0 {
+				name = nameMatch[1]
+			}
+			if versionRegex.MatchString(lines[index]) {
+				versionLine = index
+			}
+		}
+
+		version, ok := versions[name]
+		if ok && versionLine >= 0 {
+			lineParts := versionRegex.FindStringSubmatch(lines[versionLine])
+			lines[versionLine] = lineParts[1] + lineParts[2] + version + lineParts[4] + lineParts[5]
+		}
+		itemStart = -1
+	}
+
+	for index, line := range lines {
+		if yamlTopLevelRegex.MatchString(line) {
+			updateItem(index)
+			withinDependencies = strings.HasPrefix(line, "dependencies:")
+			itemIndent = ""
+			continue
+		}
+		if !withinDependencies {
+			continue
+		}
+
+		itemMatch := yamlListItemRegex.FindStringSubmatch(line)
+		if len(itemMatch) > 0 && (itemStart < 0 || itemMatch[1] == itemIndent) {
+			updateItem(index)
+			itemIndent = itemMatch[1]
+			itemStart = index
+		}
+	}
+	updateItem(len(lines))
+
+	return strings.Join(lines, "\n")
+}
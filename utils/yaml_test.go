@@ -0,0 +1,78 @@
+package utils_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MatthiasSchild/tagger/utils"
+)
+
+const chartInput = `apiVersion: v2
+name: app
+version: 0.1.0 # Comment A
+appVersion: "1.0.0"
+
+dependencies:
+  - name: common
+    version: 0.0.1
+    repository: file://../common
+  - repository: https://charts.bitnami.com/bitnami
+    name: redis
+    version: "17.0.0"
+- name: worker
+  version: '0.2.0'
+  repository: file://charts/worker
+`
+
+const chartExpected = `apiVersion: v2
+name: app
+version: 0.1.1 # Comment A
+appVersion: "1.1.0"
+
+dependencies:
+  - name: common
+    version: 0.0.2
+    repository: file://../common
+  - repository: https://charts.bitnami.com/bitnami
+    name: redis
+    version: "17.0.0"
+- name: worker
+  version: '0.3.0'
+  repository: file://charts/worker
+`
+
+func TestUpdateYaml(t *testing.T) {
+	result := utils.UpdateYamlValue(chartInput, "version", "0.1.1")
+	result = utils.UpdateYamlValue(result, "appVersion", "1.1.0")
+	result = utils.UpdateYamlDependencyVersions(result, map[string]string{
+		"common": "0.0.2",
+		"worker": "0.3.0",
+	})
+
+	resultLines := strings.Split(result, "\n")
+	expectLines := strings.Split(chartExpected, "\n")
+	if len(resultLines) != len(expectLines) {
+		t.Errorf(
+			"line numbers of the result mismatch, result=%d, expect=%d",
+			len(resultLines),
+			len(expectLines),
+		)
+		return
+	}
+
+	for index := range resultLines {
+		if resultLines[index] != expectLines[index] {
+			t.Errorf(
+				"result mismatches in line %d:\nline: %s\nexpect:%s",
+				index+1,
+				resultLines[index],
+				expectLines[index],
+			)
+			return
+		}
+	}
+
+	if version := utils.ReadYamlValue(chartInput, "appVersion"); version != "1.0.0" {
+		t.Errorf("read appVersion mismatches, result=%s, expect=1.0.0", version)
+	}
+}
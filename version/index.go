@@ -0,0 +1,133 @@
+package version
+
+import (
+	"slices"
+)
+
+// Index contains the version tags with one prefix, sorted from the lowest to the highest version.
+// It is built in a single pass over the tag names, so it stays fast for tens of thousands of tags.
+type Index struct {
+	prefix string
+	tags   []Tag
+	seen   map[[3]int]bool
+	names  map[[3]int]string
+}
+
+// NewIndex builds the index of the tag names with the given prefix.
+// Names not being a version with the prefix are ignored,
+// tags only differing in their addition are contained once without the addition.
+// The name of the first tag of each version is kept, see Name.
+func NewIndex(names []string, prefix string) *Index {
+	index := &Index{
+		prefix: prefix,
+		tags:   make([]Tag, 0),
+		seen:   make(map[[3]int]bool),
+		names:  make(map[[3]int]string),
+	}
+
+	for _, name := range names {
+		if len(name) <= len(prefix) || name[:len(prefix)] != prefix {
+			continue
+		}
+		parts, ok := parseVersionName(name[len(prefix):])
+		if !ok || index.seen[parts] {
+			continue
+		}
+		index.seen[parts] = true
+		index.names[parts] = name
+		index.tags = append(index.tags, Tag{Major: parts[0], Minor: parts[1], Patch: parts[2], Prefix: prefix})
+	}
+
+	slices.SortFunc(index.tags, Compare)
+	return index
+}
+
+// Tags returns the versions from the lowest to the highest
+func (i *Index) Tags() []Tag {
+	return i.tags
+}
+
+// Len returns the number of versions
+func (i *Index) Len() int {
+	return len(i.tags)
+}
+
+// Latest returns the highest version, the result is false for an empty index
+func (i *Index) Latest() (Tag, bool) {
+	if len(i.tags) == 0 {
+		return Tag{}, false
+	}
+	return i.tags[len(i.tags)-1], true
+}
+
+// Contains checks, if the version (ignoring its addition) is tagged
+func (i *Index) Contains(tag Tag) bool {
+	return i.seen[[3]int{tag.Major, tag.Minor, tag.Patch}]
+}
+
+// Name returns the name of the tag of the version (ignoring its addition), empty when it is not tagged.
+// It differs from the version for tags with an addition, e.g. v1.2.3-abcdef created with --hash.
+func (i *Index) Name(tag Tag) string {
+	return i.names[[3]int{tag.Major, tag.Minor, tag.Patch}]
+}
+
+// HighestInMajor checks, if no version of the index with the same major part is higher than the tag
+func (i *Index) HighestInMajor(tag Tag) bool {
+	for index := len(i.tags) - 1; index >= 0; index-- {
+		if i.tags[index].Major == tag.Major {
+			return Compare(i.tags[index], tag) <= 0
+		}
+	}
+	return true
+}
+
+// HighestInMinor checks, if no version of the index with the same major and minor part is higher than the tag
+func (i *Index) HighestInMinor(tag Tag) bool {
+	for index := len(i.tags) - 1; index >= 0; index-- {
+		if i.tags[index].Major == tag.Major && i.tags[index].Minor == tag.Minor {
+			return Compare(i.tags[index], tag) <= 0
+		}
+	}
+	return true
+}
+
+// parseVersionName parses "major.minor.patch" with an optional addition ("+abc" or "-abc"),
+// which is the part of a tag name after the prefix.
+// It replaces a regular expression, because it runs for every tag of the repository.
+func parseVersionName(value string) ([3]int, bool) {
+	var parts [3]int
+	position := 0
+
+	for part := 0; part < 3; part++ {
+		start := position
+		number := 0
+		for position < len(value) && value[position] >= '0' && value[position] <= '9' {
+			number = number*10 + int(value[position]-'0')
+			position++
+		}
+		if position == start || position-start > 18 {
+			return parts, false
+		}
+		parts[part] = number
+
+		if part < 2 {
+			if position >= len(value) || value[position] != '.' {
+				return parts, false
+			}
+			position++
+		}
+	}
+
+	if position == len(value) {
+		return parts, true
+	}
+	if value[position] != '+' && value[position] != '-' || position+1 == len(value) {
+		return parts, false
+	}
+	for _, char := range value[position+1:] {
+		if !(char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char >= '0' && char <= '9') {
+			return parts, false
+		}
+	}
+	return parts, true
+}
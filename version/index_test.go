@@ -0,0 +1,156 @@
+package version_test
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/MatthiasSchild/tagger/version"
+)
+
+func TestIndex(t *testing.T) {
+	names := []string{
+		"v1.10.0", "v1.2.0-abc", "v1.2.0+1", "v1.2.0", "v0.9.12",
+		"v", "v1.2", "v1.2.3-", "v1.2.3-a.b", "v1.2.3.4", "va.b.c", "api/v9.0.0",
+	}
+
+	index := version.NewIndex(names, "v")
+	result := make([]string, 0)
+	for _, tag := range index.Tags() {
+		result = append(result, tag.String())
+	}
+	if !slices.Equal(result, []string{"v0.9.12", "v1.2.0", "v1.10.0"}) {
+		t.Errorf("unexpected tags %v", result)
+	}
+
+	latest, ok := index.Latest()
+	if !ok || latest.String() != "v1.10.0" {
+		t.Errorf("unexpected latest tag %s", latest)
+	}
+	if !index.Contains(version.Tag{Major: 1, Minor: 2, MinusAddition: "xyz"}) {
+		t.Error("v1.2.0 should be contained")
+	}
+	if index.Contains(version.Tag{Major: 9}) {
+		t.Error("api/v9.0.0 should not be contained")
+	}
+
+	_, ok = version.NewIndex(nil, "v").Latest()
+	if ok {
+		t.Error("an empty index should have no latest tag")
+	}
+}
+
+func TestIndexName(t *testing.T) {
+	index := version.NewIndex([]string{"api/v1.2.3-abcdef", "api/v1.3.0", "v2.0.0"}, "api/v")
+
+	if name := index.Name(version.Tag{Major: 1, Minor: 2, Patch: 3}); name != "api/v1.2.3-abcdef" {
+		t.Errorf("expected the name of the hashed tag, got %q", name)
+	}
+	if name := index.Name(version.Tag{Major: 2}); name != "" {
+		t.Errorf("expected no name for a version of another prefix, got %q", name)
+	}
+}
+
+func TestIndexAliases(t *testing.T) {
+	index := version.NewIndex([]string{"v1", "v1.2", "v1.2.0", "v1.3.1", "v2.0.0", "v2"}, "v")
+	if index.Len() != 3 {
+		t.Errorf("the aliases should be ignored, got %v", index.Tags())
+	}
+
+	tag := version.Tag{Major: 1, Minor: 2, Patch: 5}
+	if tag.MajorAlias() != "v1" || tag.MinorAlias() != "v1.2" {
+		t.Errorf("unexpected aliases %s and %s", tag.MajorAlias(), tag.MinorAlias())
+	}
+	if index.HighestInMajor(tag) || !index.HighestInMinor(tag) {
+		t.Error("v1.2.5 should only be the highest of v1.2")
+	}
+	tag = version.Tag{Major: 1, Minor: 3, Patch: 1}
+	if !index.HighestInMajor(tag) || !index.HighestInMinor(tag) {
+		t.Error("the tagged v1.3.1 should be the highest of v1 and v1.3")
+	}
+	if !index.HighestInMajor(version.Tag{Major: 3}) {
+		t.Error("a new major line should be the highest")
+	}
+}
+
+// nightlyTagNames builds tag names like in a repository with nightly builds:
+// every version is tagged multiple times with a build addition, mixed with other tags.
+func nightlyTagNames(count int) []string {
+	result := make([]string, 0, count)
+	for i := 0; len(result) < count; i++ {
+		major, minor, patch := i/10000, i/100%100, i%100
+		result = append(result,
+			fmt.Sprintf("v%d.%d.%d", major, minor, patch),
+			fmt.Sprintf("v%d.%d.%d-nightly%d", major, minor, patch, i),
+			fmt.Sprintf("v%d.%d.%d+%d", major, minor, patch, i),
+			fmt.Sprintf("build-%d", i),
+		)
+	}
+	return result[:count]
+}
+
+// legacyParseTags is the previous implementation, which is kept to compare the performance
+func legacyParseTags(rawTags []string, prefix string) version.Tag {
+	result := make([]version.Tag, 0)
+	cleanTags := make([]string, 0)
+
+	for _, tag := range rawTags {
+		pattern := "^" + regexp.QuoteMeta(prefix) + "([0-9]+)\\.([0-9]+)\\.([0-9]+)([+-][a-zA-Z0-9]+)?$"
+		matched, err := regexp.MatchString(pattern, tag)
+		if err != nil {
+			continue
+		}
+		if matched {
+			version := strings.TrimPrefix(tag, prefix)
+			cleanTag := strings.SplitN(strings.SplitN(version, "+", 2)[0], "-", 2)[0]
+			if !slices.Contains(cleanTags, cleanTag) {
+				cleanTags = append(cleanTags, cleanTag)
+			}
+		}
+	}
+
+	for _, tag := range cleanTags {
+		var major, minor, patch int
+		_, err := fmt.Sscanf(tag, "%d.%d.%d", &major, &minor, &patch)
+		if err != nil {
+			continue
+		}
+		result = append(result, version.Tag{Major: major, Minor: minor, Patch: patch, Prefix: prefix})
+	}
+
+	return version.Latest(result)
+}
+
+func TestIndexMatchesLegacy(t *testing.T) {
+	names := nightlyTagNames(2000)
+
+	latest, _ := version.NewIndex(names, "v").Latest()
+	if expected := legacyParseTags(names, "v"); latest != expected {
+		t.Errorf("expected latest tag %s, got %s", expected, latest)
+	}
+}
+
+func BenchmarkIndex(b *testing.B) {
+	for _, count := range []int{1000, 10000, 50000} {
+		names := nightlyTagNames(count)
+		b.Run(fmt.Sprint(count), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				version.NewIndex(names, "v").Latest()
+			}
+		})
+	}
+}
+
+func BenchmarkLegacyParseTags(b *testing.B) {
+	// The legacy implementation is quadratic, so it is only measured up to 10000 tags
+	for _, count := range []int{1000, 10000} {
+		names := nightlyTagNames(count)
+		b.Run(fmt.Sprint(count), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				legacyParseTags(names, "v")
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Package version contains the version tags of tagger (e.g. v1.2.3): parsing, comparing and bumping.
+//
+// Together with the packages targets and release, it is the public Go API of tagger.
+// The API follows semantic import versioning: incompatible changes are only made
+// with a new major version of the module path (github.com/MatthiasSchild/tagger/v2).
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultPrefix is the prefix of the version tags (e.g. "v" for v1.2.3).
+// It is used for tags without an own prefix.
+const DefaultPrefix = "v"
+
+// versionRegex matches a version with an optional "v" and addition (e.g. 1.2.3, v1.2.3 or 1.2.3+4).
+// The groups are major, minor, patch, the addition including its separator and the addition.
+var versionRegex = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)([+-]([a-zA-Z0-9]+))?$`)
+
+// Tag contains the individual information about a 3-part tag with optional addition
+type Tag struct {
+	Major         int
+	Minor         int
+	Patch         int
+	PlusAddition  string
+	MinusAddition string
+	Prefix        string
+}
+
+// Parse parses a version with an optional "v" and addition (e.g. 1.2.3, v1.2.3-rc1 or 1.2.3+4).
+// The tag has no prefix, so the default prefix is used when it is printed.
+func Parse(value string) (Tag, error) {
+	groups := versionRegex.FindStringSubmatch(value)
+	if groups == nil {
+		return Tag{}, fmt.Errorf("invalid version %s, the version must be in the format 1.2.3", value)
+	}
+
+	major, _ := strconv.Atoi(groups[1])
+	minor, _ := strconv.Atoi(groups[2])
+	patch, _ := strconv.Atoi(groups[3])
+	result := Tag{Major: major, Minor: minor, Patch: patch}
+	if strings.HasPrefix(groups[4], "+") {
+		result.PlusAddition = groups[5]
+	} else if strings.HasPrefix(groups[4], "-") {
+		result.MinusAddition = groups[5]
+	}
+	return result, nil
+}
+
+// ParseTags parses the names of git tags, see NewIndex.
+// The versions are sorted from the lowest to the highest.
+func ParseTags(names []string, prefix string) []Tag {
+	return NewIndex(names, prefix).Tags()
+}
+
+// Latest returns the highest version of the tags, ignoring the additions.
+// For an empty list, the zero Tag is returned.
+func Latest(tags []Tag) Tag {
+	var latest Tag
+	for _, tag := range tags {
+		if tag.Major > latest.Major {
+			latest = tag
+		} else if tag.Major == latest.Major {
+			if tag.Minor > latest.Minor {
+				latest = tag
+			} else if tag.Minor == latest.Minor {
+				if tag.Patch > latest.Patch {
+					latest = tag
+				}
+			}
+		}
+	}
+	return latest
+}
+
+// Compare compares the major, minor and patch part of two versions.
+// The result is negative, when a is lower than b, positive when it is higher and 0 when they are equal.
+func Compare(a Tag, b Tag) int {
+	if a.Major != b.Major {
+		return a.Major - b.Major
+	}
+	if a.Minor != b.Minor {
+		return a.Minor - b.Minor
+	}
+	return a.Patch - b.Patch
+}
+
+// String builds the string dependent on the values of the structure,
+// resulting in a 3-part version (e.g. v1.2.3).
+// When addition is set, it will be added with a hyphen (e.g. v1.2.3-1fa342)
+func (t Tag) String() string {
+	result := t.StringSimple()
+
+	if len(t.PlusAddition) > 0 {
+		result += "+" + t.PlusAddition
+	}
+
+	if len(t.MinusAddition) > 0 {
+		result += "-" + t.MinusAddition
+	}
+
+	return result
+}
+
+// String builds the string dependent on the values of the structure,
+// resulting in a 3-part version (e.g. v1.2.3).
+// When the tag has no own prefix, the default prefix is used.
+func (t Tag) StringSimple() string {
+	return t.prefix() + t.Version()
+}
+
+// Version builds the 3-part version without prefix and addition (e.g. 1.2.3),
+// as it is written into the version files.
+func (t Tag) Version() string {
+	return fmt.Sprintf("%d.%d.%d", t.Major, t.Minor, t.Patch)
+}
+
+// MajorAlias returns the floating tag of the major line of the version (e.g. v1 for v1.2.3)
+func (t Tag) MajorAlias() string {
+	return fmt.Sprintf("%s%d", t.prefix(), t.Major)
+}
+
+// MinorAlias returns the floating tag of the minor line of the version (e.g. v1.2 for v1.2.3)
+func (t Tag) MinorAlias() string {
+	return fmt.Sprintf("%s%d.%d", t.prefix(), t.Major, t.Minor)
+}
+
+// prefix returns the prefix of the tag, the default prefix for tags without an own one
+func (t Tag) prefix() string {
+	if t.Prefix == "" {
+		return DefaultPrefix
+	}
+	return t.Prefix
+}
+
+// Equals checks if the major, minor and the patch part of two versions are equal.
+// The addition part will be ignored.
+func (t Tag) Equals(tag Tag) bool {
+	return (t.Major == tag.Major) && (t.Minor == tag.Minor) && (t.Patch == tag.Patch)
+}
+
+// Clone clones the Tag structure.
+// The addition will be removed from the copy.
+func (t Tag) Clone() Tag {
+	return Tag{
+		Major:  t.Major,
+		Minor:  t.Minor,
+		Patch:  t.Patch,
+		Prefix: t.Prefix,
+	}
+}
+
+// Bump returns a copy of the tag with the given part ("major", "minor" or "patch") increased.
+// The following parts will be set to 0 and the addition will be removed.
+func (t Tag) Bump(part string) Tag {
+	result := t.Clone()
+	switch part {
+	case "major":
+		result.Major++
+		result.Minor = 0
+		result.Patch = 0
+	case "minor":
+		result.Minor++
+		result.Patch = 0
+	case "patch":
+		result.Patch++
+	}
+	return result
+}
@@ -0,0 +1,67 @@
+package version_test
+
+import (
+	"testing"
+
+	"github.com/MatthiasSchild/tagger/version"
+)
+
+func TestParse(t *testing.T) {
+	tag, err := version.Parse("v1.2.3-rc1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := version.Tag{Major: 1, Minor: 2, Patch: 3, MinusAddition: "rc1"}
+	if tag != expected {
+		t.Errorf("unexpected tag %+v", tag)
+	}
+
+	tag, _ = version.Parse("1.2.3+4")
+	if tag.PlusAddition != "4" {
+		t.Errorf("unexpected plus addition %s", tag.PlusAddition)
+	}
+
+	// The dots are matched literally, not escaped twice
+	for _, invalid := range []string{"1.2", "v1.2.3.4", "x1.2.3", "", `1\.2\.3`, "1x2y3"} {
+		if _, err := version.Parse(invalid); err == nil {
+			t.Errorf("%q should not be parsed", invalid)
+		}
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	names := []string{"v1.0.0", "v1.10.0", "v1.2.0-abc", "v1.2.0+1", "api/v3.0.0", "release", "v2"}
+
+	tags := version.ParseTags(names, "v")
+	if len(tags) != 3 {
+		t.Fatalf("expected 3 tags, got %v", tags)
+	}
+	latest := version.Latest(tags)
+	if latest.String() != "v1.10.0" {
+		t.Errorf("expected latest v1.10.0, got %s", latest)
+	}
+
+	tags = version.ParseTags(names, "api/v")
+	if len(tags) != 1 || tags[0].String() != "api/v3.0.0" {
+		t.Errorf("unexpected component tags %v", tags)
+	}
+}
+
+func TestCompareAndBump(t *testing.T) {
+	tag := version.Tag{Major: 1, Minor: 2, Patch: 3, MinusAddition: "abc"}
+
+	cases := map[string]string{"major": "v2.0.0", "minor": "v1.3.0", "patch": "v1.2.4", "": "v1.2.3"}
+	for part, expected := range cases {
+		bumped := tag.Bump(part)
+		if bumped.String() != expected {
+			t.Errorf("bump %q: expected %s, got %s", part, expected, bumped)
+		}
+		if part != "" && version.Compare(bumped, tag) <= 0 {
+			t.Errorf("bump %q: %s should be higher than %s", part, bumped, tag)
+		}
+	}
+
+	if version.Compare(tag, tag.Clone()) != 0 {
+		t.Error("additions should be ignored when comparing")
+	}
+}